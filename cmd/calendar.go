@@ -0,0 +1,340 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var calendarCmd = &cobra.Command{
+	Use:   "calendar",
+	Short: "Export upcoming fixtures as an iCalendar file",
+	Long: `The 'calendar' command writes the upcoming matches of a team or league as an
+RFC 5545 calendar that can be imported into Google Calendar, Outlook or
+any other calendar app. Every match keeps the same UID across exports, so
+re-exporting into an existing file updates changed kickoff times instead
+of creating duplicates.
+
+Examples:
+  # Export Arsenal's fixtures
+  sharingan calendar --team "Arsenal" --out arsenal.ics
+
+  # Export the next 60 days of Premier League fixtures
+  sharingan calendar --league eng.1 --days 60 --out premier-league.ics
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return exportCalendar(cmd.Context())
+	},
+}
+
+var (
+	calendarOut  string
+	calendarDays int
+)
+
+func init() {
+	rootCmd.AddCommand(calendarCmd)
+
+	calendarCmd.Flags().StringVarP(&team, "team", "t", "", "Team name or abbreviation")
+	calendarCmd.Flags().StringVarP(&league, "league", "l", "", "League slug (e.g. eng.1)")
+	calendarCmd.Flags().IntVar(&calendarDays, "days", 90, "Number of days ahead to export for a league")
+	calendarCmd.Flags().StringVarP(&calendarOut, "out", "o", "", "Output file (default: stdout)")
+}
+
+// matchDuration is the calendar length of a match, including half-time
+const matchDuration = 2 * time.Hour
+
+// calendarEvent is one VEVENT of the calendar
+type calendarEvent struct {
+	UID      string
+	Start    time.Time
+	Summary  string
+	Location string
+	Details  string
+	Sequence int
+	raw      string
+}
+
+func exportCalendar(ctx context.Context) error {
+	if (team == "") == (league == "") {
+		return errInvalidInput("please provide either --team or --league")
+	}
+
+	var events []Event
+	var name, competition string
+	var err error
+
+	if team != "" {
+		found, ok, err := findTeam(ctx, team)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errNotFound("team '%s' not found. Please check the name or abbreviation.", team)
+		}
+		name = found.DisplayName + " fixtures"
+		events, err = fetchTeamSchedule(ctx, found.ID, true)
+		if err != nil {
+			return fmt.Errorf("fetching fixtures: %w", err)
+		}
+	} else {
+		now := time.Now()
+		name = strings.ToUpper(league) + " fixtures"
+		competition = league
+		events, err = fetchLeagueEvents(ctx, league, now, now.AddDate(0, 0, calendarDays))
+		if err != nil {
+			return fmt.Errorf("fetching fixtures: %w", err)
+		}
+	}
+
+	var entries []calendarEvent
+	for _, event := range events {
+		if event.Status.Type.State != "pre" {
+			continue
+		}
+		if entry, ok := newCalendarEvent(event, competition); ok {
+			entries = append(entries, entry)
+		}
+	}
+
+	if calendarOut == "" {
+		fmt.Print(renderCalendar(name, entries))
+		return nil
+	}
+
+	existing, err := readCalendar(calendarOut)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", calendarOut, err)
+	}
+	merged, updated := mergeCalendar(existing, entries)
+
+	if err := os.WriteFile(calendarOut, []byte(renderCalendar(name, merged)), 0644); err != nil {
+		return fmt.Errorf("writing %s: %w", calendarOut, err)
+	}
+	fmt.Printf("Wrote %d fixtures to %s (%d new, %d updated)\n", len(entries), calendarOut, len(merged)-len(existing), updated)
+	return nil
+}
+
+// newCalendarEvent converts an ESPN event into a calendar entry
+func newCalendarEvent(event Event, competition string) (calendarEvent, bool) {
+	start, err := parseEventTime(event.Date)
+	if err != nil || len(event.Competitions) == 0 {
+		return calendarEvent{}, false
+	}
+
+	c := event.Competitions[0]
+	summary := event.Name
+	if home, away, ok := homeAndAway(c); ok {
+		summary = fmt.Sprintf("%s vs %s", home.Team.DisplayName, away.Team.DisplayName)
+	}
+
+	var location []string
+	for _, part := range []string{c.Venue.FullName, c.Venue.Address.City, c.Venue.Address.Country} {
+		if part != "" {
+			location = append(location, part)
+		}
+	}
+
+	if event.League.Name != "" {
+		competition = event.League.Name
+	}
+	details := "Competition: " + defaultIfEmpty(competition, "Unknown")
+	for _, note := range c.Notes {
+		if note.Headline != "" {
+			details += "\n" + note.Headline
+		}
+	}
+
+	return calendarEvent{
+		UID:      event.ID + "@sharingan",
+		Start:    start.UTC(),
+		Summary:  summary,
+		Location: strings.Join(location, ", "),
+		Details:  details,
+	}, true
+}
+
+// readCalendar loads the events of a previous export. A missing file is an
+// empty calendar.
+func readCalendar(path string) ([]calendarEvent, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	// Unfold continuation lines first (RFC 5545 section 3.1)
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	var events []calendarEvent
+	var current *calendarEvent
+	var raw []string
+	for _, line := range lines {
+		switch {
+		case line == "BEGIN:VEVENT":
+			current = &calendarEvent{}
+			raw = nil
+		case line == "END:VEVENT" && current != nil:
+			raw = append(raw, line)
+			current.raw = strings.Join(raw, "\n")
+			events = append(events, *current)
+			current = nil
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		raw = append(raw, line)
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key, _, _ = strings.Cut(key, ";")
+		value = unescapeICS(value)
+		switch key {
+		case "UID":
+			current.UID = value
+		case "DTSTART":
+			current.Start, _ = time.Parse("20060102T150405Z", value)
+		case "SUMMARY":
+			current.Summary = value
+		case "LOCATION":
+			current.Location = value
+		case "DESCRIPTION":
+			current.Details = value
+		case "SEQUENCE":
+			current.Sequence, _ = strconv.Atoi(value)
+		}
+	}
+	return events, nil
+}
+
+// mergeCalendar combines a previous export with freshly fetched events.
+// Events are matched by UID; a changed event keeps its UID and gets a
+// higher SEQUENCE so calendar apps replace it. Events no longer returned
+// by the API (e.g. matches already played) are kept as they were.
+func mergeCalendar(existing, fresh []calendarEvent) ([]calendarEvent, int) {
+	byUID := make(map[string]int, len(existing))
+	merged := append([]calendarEvent(nil), existing...)
+	for i, e := range merged {
+		byUID[e.UID] = i
+	}
+
+	updated := 0
+	for _, e := range fresh {
+		i, ok := byUID[e.UID]
+		if !ok {
+			byUID[e.UID] = len(merged)
+			merged = append(merged, e)
+			continue
+		}
+
+		old := merged[i]
+		e.Sequence = old.Sequence
+		if !old.Start.Equal(e.Start) || old.Summary != e.Summary || old.Location != e.Location || old.Details != e.Details {
+			e.Sequence++
+			updated++
+			merged[i] = e
+		}
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool { return merged[i].Start.Before(merged[j].Start) })
+	return merged, updated
+}
+
+// renderCalendar writes the events as an RFC 5545 calendar
+func renderCalendar(name string, events []calendarEvent) string {
+	var sb strings.Builder
+	write := func(line string) {
+		sb.WriteString(foldICS(line))
+		sb.WriteString("\r\n")
+	}
+
+	write("BEGIN:VCALENDAR")
+	write("VERSION:2.0")
+	write("PRODID:-//sharingan//fixtures//EN")
+	write("CALSCALE:GREGORIAN")
+	write("METHOD:PUBLISH")
+	write("X-WR-CALNAME:" + escapeICS(name))
+
+	stamp := time.Now().UTC().Format("20060102T150405Z")
+	for _, e := range events {
+		// Unchanged events from a previous export are written back verbatim
+		if e.raw != "" {
+			for _, line := range strings.Split(e.raw, "\n") {
+				write(line)
+			}
+			continue
+		}
+
+		write("BEGIN:VEVENT")
+		write("UID:" + e.UID)
+		write("DTSTAMP:" + stamp)
+		write("DTSTART:" + e.Start.Format("20060102T150405Z"))
+		write("DTEND:" + e.Start.Add(matchDuration).Format("20060102T150405Z"))
+		write("SEQUENCE:" + strconv.Itoa(e.Sequence))
+		write("SUMMARY:" + escapeICS(e.Summary))
+		if e.Location != "" {
+			write("LOCATION:" + escapeICS(e.Location))
+		}
+		write("DESCRIPTION:" + escapeICS(e.Details))
+		write("STATUS:CONFIRMED")
+		write("END:VEVENT")
+	}
+
+	write("END:VCALENDAR")
+	return sb.String()
+}
+
+// escapeICS escapes a TEXT value (RFC 5545 section 3.3.11)
+func escapeICS(value string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(value)
+}
+
+// unescapeICS reverses escapeICS
+func unescapeICS(value string) string {
+	return strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n").Replace(value)
+}
+
+// foldICS splits lines longer than 75 octets without breaking UTF-8 sequences
+func foldICS(line string) string {
+	if len(line) <= 75 {
+		return line
+	}
+
+	var sb strings.Builder
+	width := 0
+	for _, r := range line {
+		size := len(string(r))
+		if width+size > 75 {
+			sb.WriteString("\r\n ")
+			width = 1
+		}
+		sb.WriteRune(r)
+		width += size
+	}
+	return sb.String()
+}
@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// config is the user's settings file
+type config struct {
+	// Favourites are team names, abbreviations or IDs
+	Favourites []string `json:"favourites,omitempty"`
+	// Leagues are league slugs (e.g. eng.1) covered by digests
+	Leagues []string `json:"leagues,omitempty"`
+	// Notifiers are named outputs that alert rules send to
+	Notifiers map[string]notifierConfig `json:"notifiers,omitempty"`
+	// Rules are alert rules evaluated against match events
+	Rules []alertRule `json:"rules,omitempty"`
+	// Bus configures the message brokers match events are published to
+	Bus busConfig `json:"bus,omitempty"`
+	// Fallback is an ESPN-compatible mirror (e.g. https://site.web.api.espn.com)
+	// used while ESPN is failing
+	Fallback string `json:"fallback,omitempty"`
+}
+
+// busConfig lists the brokers to publish to
+type busConfig struct {
+	MQTT *brokerConfig `json:"mqtt,omitempty"`
+	NATS *brokerConfig `json:"nats,omitempty"`
+}
+
+// brokerConfig is a broker and the topics (subjects, for NATS) to publish
+// on. Topics may use the placeholders {league}, {match}, {type}, {home}
+// and {away}.
+type brokerConfig struct {
+	URL        string `json:"url"`
+	ClientID   string `json:"clientId,omitempty"`
+	Topic      string `json:"topic,omitempty"`
+	StateTopic string `json:"stateTopic,omitempty"`
+}
+
+// notifierConfig describes a notifier in the config file
+type notifierConfig struct {
+	// Type is webhook, slack, discord or exec
+	Type    string `json:"type"`
+	URL     string `json:"url,omitempty"`
+	Command string `json:"command,omitempty"`
+}
+
+// alertRule sends an event to notifiers when its condition holds
+type alertRule struct {
+	Name string `json:"name"`
+	When string `json:"when"`
+	// Notify names the notifiers to use; empty means all of them
+	Notify []string `json:"notify,omitempty"`
+}
+
+// build creates the notifier described by the config
+func (n notifierConfig) build() (notifier, error) {
+	if n.Type == "exec" && n.Command == "" {
+		return nil, errInvalidInput("exec notifier needs a command")
+	}
+	if n.Type != "exec" && n.URL == "" {
+		return nil, errInvalidInput("%s notifier needs a url", n.Type)
+	}
+
+	switch n.Type {
+	case "webhook":
+		return webhookNotifier{n.URL}, nil
+	case "slack":
+		return slackNotifier{n.URL}, nil
+	case "discord":
+		return discordNotifier{n.URL}, nil
+	case "exec":
+		return commandNotifier{n.Command}, nil
+	}
+	return nil, errInvalidInput("unknown notifier type %q", n.Type)
+}
+
+// configPath returns the --config file or the default location
+func configPath() (string, error) {
+	if configFile != "" {
+		return configFile, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("locating config directory: %w", err)
+	}
+	return filepath.Join(dir, "sharingan", "config.json"), nil
+}
+
+// statePath returns the location of a state file kept between runs
+func statePath(name string) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("locating cache directory: %w", err)
+	}
+	return filepath.Join(dir, "sharingan", name), nil
+}
+
+// loadConfig reads the config file. A missing file is an empty config.
+func loadConfig() (*config, error) {
+	path, err := configPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &config{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// save writes the config file, creating its directory if needed
+func (c *config) save() error {
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	// The file can hold webhook URLs and credentials, so keep it private,
+	// including when it was created by an older version
+	if err := os.WriteFile(path, append(data, '\n'), 0600); err != nil {
+		return err
+	}
+	return os.Chmod(path, 0600)
+}
+
+// isFavourite reports whether a team is one of the favourites
+func (c *config) isFavourite(t Team) bool {
+	for _, fav := range c.Favourites {
+		if fav == t.ID || strings.EqualFold(fav, t.DisplayName) || strings.EqualFold(fav, t.Abbreviation) {
+			return true
+		}
+	}
+	return false
+}
+
+// toggleFavourite adds or removes a team and reports whether it is now a favourite
+func (c *config) toggleFavourite(t Team) bool {
+	for i, fav := range c.Favourites {
+		if fav == t.ID || strings.EqualFold(fav, t.DisplayName) || strings.EqualFold(fav, t.Abbreviation) {
+			c.Favourites = append(c.Favourites[:i], c.Favourites[i+1:]...)
+			return false
+		}
+	}
+	c.Favourites = append(c.Favourites, t.DisplayName)
+	return true
+}
@@ -0,0 +1,517 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log/slog"
+	"net/smtp"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// digestCmd represents the digest command
+var digestCmd = &cobra.Command{
+	Use:   "digest",
+	Short: "Summarise recent results, upcoming fixtures and table movements",
+	Long: `The 'digest' command gathers the results of the last day or week, the
+fixtures of the next one and how the tables moved, for the leagues and
+teams you follow. Leagues and teams come from the flags or from the
+"leagues" and "favourites" entries of the config file.
+
+The digest is rendered as Markdown, HTML or plain text and written to
+stdout, a file, a webhook or sent by email. The SMTP password is read from
+the SHARINGAN_SMTP_PASSWORD environment variable.
+
+With --schedule the command keeps running and sends a digest whenever the
+cron expression (minute hour day-of-month month day-of-week) matches.
+
+Examples:
+  # Yesterday's Premier League results and today's fixtures
+  sharingan digest --league eng.1
+
+  # A weekly HTML digest of your favourites, written to a file
+  sharingan digest --period weekly --format html --out digest.html
+
+  # Email a digest every morning at 8
+  sharingan digest --schedule "0 8 * * *" --smtp smtp.example.com:587 --from me@example.com --to me@example.com
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runDigest(cmd.Context())
+	},
+}
+
+var (
+	digestPeriod   string
+	digestLeagues  []string
+	digestFormat   string
+	digestOut      string
+	digestWebhook  string
+	digestSchedule string
+	smtpServer     string
+	smtpUser       string
+	smtpFrom       string
+	smtpTo         []string
+)
+
+func init() {
+	rootCmd.AddCommand(digestCmd)
+
+	digestCmd.Flags().StringVar(&digestPeriod, "period", "daily", "Period covered (daily, weekly)")
+	digestCmd.Flags().StringSliceVarP(&digestLeagues, "league", "l", nil, "League slugs to cover (default: leagues from the config)")
+	digestCmd.Flags().StringSliceVarP(&notifyTeams, "team", "t", nil, "Teams to cover (default: favourites from the config)")
+	digestCmd.Flags().StringVarP(&digestFormat, "format", "f", "markdown", "Output format (markdown, html, text)")
+	digestCmd.Flags().StringVarP(&digestOut, "out", "o", "", "Write the digest to a file")
+	digestCmd.Flags().StringVar(&digestWebhook, "webhook", "", "POST the digest as JSON to a URL")
+	digestCmd.Flags().StringVar(&digestSchedule, "schedule", "", "Cron expression to send digests on, e.g. \"0 8 * * *\"")
+	digestCmd.Flags().StringVar(&smtpServer, "smtp", "", "SMTP server (host:port) to email the digest through")
+	digestCmd.Flags().StringVar(&smtpUser, "smtp-user", "", "SMTP username (default: --from)")
+	digestCmd.Flags().StringVar(&smtpFrom, "from", "", "Sender address for emailed digests")
+	digestCmd.Flags().StringSliceVar(&smtpTo, "to", nil, "Recipients of emailed digests")
+}
+
+// digest is the content of one digest, independent of its format
+type digest struct {
+	Title    string
+	From, To time.Time
+	Sections []digestSection
+}
+
+// digestSection covers one league or team
+type digestSection struct {
+	Name      string
+	Results   []matchResult
+	Fixtures  []Event
+	Movements []tableMovement
+}
+
+// tableMovement is a team that changed position over the period
+type tableMovement struct {
+	Team     string
+	From, To int
+	Points   int
+}
+
+func runDigest(ctx context.Context) error {
+	period, err := digestDuration(digestPeriod)
+	if err != nil {
+		return err
+	}
+	if err := validateDigestOptions(); err != nil {
+		return err
+	}
+
+	if digestSchedule == "" {
+		return buildAndDeliverDigest(ctx, period)
+	}
+
+	schedule, err := parseCron(digestSchedule)
+	if err != nil {
+		return err
+	}
+
+	for {
+		next := schedule.next(time.Now())
+		if next.IsZero() {
+			return errInvalidInput("schedule %q never matches", digestSchedule)
+		}
+		fmt.Printf("Next digest at %s\n", next.Format("Mon 2 Jan 15:04"))
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+
+		if err := buildAndDeliverDigest(ctx, period); err != nil {
+			slog.Error("sending digest failed", "err", err)
+		}
+	}
+}
+
+// validateDigestOptions checks the format and delivery settings up front,
+// so a mistake isn't only found once a scheduled digest is due
+func validateDigestOptions() error {
+	switch digestFormat {
+	case "markdown", "md", "html", "text":
+	default:
+		return errInvalidInput("unknown format %q (use markdown, html or text)", digestFormat)
+	}
+	if smtpServer != "" && (smtpFrom == "" || len(smtpTo) == 0) {
+		return errInvalidInput("emailing a digest needs --from and --to")
+	}
+	if digestWebhook != "" {
+		if u, err := url.Parse(digestWebhook); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errInvalidInput("--webhook must be an http or https URL")
+		}
+	}
+	return nil
+}
+
+func digestDuration(period string) (time.Duration, error) {
+	switch period {
+	case "daily":
+		return 24 * time.Hour, nil
+	case "weekly":
+		return 7 * 24 * time.Hour, nil
+	default:
+		return 0, errInvalidInput("unknown period %q (use daily or weekly)", period)
+	}
+}
+
+func buildAndDeliverDigest(ctx context.Context, period time.Duration) error {
+	d, err := buildDigest(ctx, period)
+	if err != nil {
+		return err
+	}
+
+	var body string
+	switch digestFormat {
+	case "markdown", "md":
+		body = renderDigestMarkdown(d)
+	case "html":
+		body = renderDigestHTML(d)
+	case "text":
+		body = renderDigestText(d)
+	default:
+		return errInvalidInput("unknown format %q (use markdown, html or text)", digestFormat)
+	}
+	return deliverDigest(ctx, d.Title, body)
+}
+
+// buildDigest gathers the results of the last period, the fixtures of the
+// next one and the table movements of each followed league
+func buildDigest(ctx context.Context, period time.Duration) (*digest, error) {
+	leagues, teams := digestLeagues, notifyTeams
+	if len(leagues) == 0 || len(teams) == 0 {
+		cfg, err := loadConfig()
+		if err != nil {
+			return nil, err
+		}
+		if len(leagues) == 0 {
+			leagues = cfg.Leagues
+		}
+		if len(teams) == 0 && len(digestLeagues) == 0 {
+			teams = cfg.Favourites
+		}
+	}
+	if len(leagues) == 0 && len(teams) == 0 {
+		return nil, errInvalidInput("nothing to cover: use --league/--team or add leagues and favourites to the config")
+	}
+
+	now := time.Now()
+	d := &digest{From: now.Add(-period), To: now}
+	d.Title = fmt.Sprintf("Football digest %s - %s", d.From.Format("2 Jan"), d.To.Format("2 Jan 2006"))
+
+	for _, slug := range leagues {
+		section, err := leagueDigest(ctx, slug, d.From, now, period)
+		if err != nil {
+			slog.Warn("skipping league", "league", slug, "err", err)
+			continue
+		}
+		d.Sections = append(d.Sections, section)
+	}
+
+	for _, name := range teams {
+		section, err := teamDigest(ctx, name, d.From, now, period)
+		if err != nil {
+			slog.Warn("skipping team", "team", name, "err", err)
+			continue
+		}
+		d.Sections = append(d.Sections, section)
+	}
+	return d, nil
+}
+
+// leagueDigest covers a league: results in [from, now), fixtures in the
+// next period and how the table changed since from
+func leagueDigest(ctx context.Context, slug string, from, now time.Time, period time.Duration) (digestSection, error) {
+	season, start, _, err := fetchCurrentSeason(ctx, slug)
+	if err != nil {
+		return digestSection{}, err
+	}
+	events, err := fetchLeagueEvents(ctx, slug, start, now.Add(period))
+	if err != nil {
+		return digestSection{}, err
+	}
+
+	section := digestSection{Name: defaultIfEmpty(season.DisplayName, slug)}
+	results := completedResults(events, slug)
+
+	var before []matchResult
+	for _, r := range results {
+		if r.Date.Before(from) {
+			before = append(before, r)
+		} else {
+			section.Results = append(section.Results, r)
+		}
+	}
+	section.Fixtures = fixturesBetween(events, now, now.Add(period))
+
+	rules, err := tiebreakRulesFor(slug, nil)
+	if err != nil {
+		return digestSection{}, err
+	}
+	previous := make(map[string]int)
+	for _, row := range computeStandings(before, rules, nil) {
+		previous[row.Team.ID] = row.Position
+	}
+	for _, row := range computeStandings(results, rules, nil) {
+		if was, ok := previous[row.Team.ID]; ok && was != row.Position {
+			section.Movements = append(section.Movements, tableMovement{
+				Team: row.Team.DisplayName, From: was, To: row.Position, Points: row.Points,
+			})
+		}
+	}
+	return section, nil
+}
+
+// teamDigest covers a single team's results and fixtures
+func teamDigest(ctx context.Context, name string, from, now time.Time, period time.Duration) (digestSection, error) {
+	found, ok, err := findTeam(ctx, name)
+	if err != nil {
+		return digestSection{}, err
+	}
+	if !ok {
+		return digestSection{}, errNotFound("team %q not found", name)
+	}
+
+	section := digestSection{Name: found.DisplayName}
+
+	played, err := fetchTeamSchedule(ctx, found.ID, false)
+	if err != nil {
+		return digestSection{}, err
+	}
+	for _, r := range completedResults(played, "") {
+		if !r.Date.Before(from) {
+			section.Results = append(section.Results, r)
+		}
+	}
+
+	upcoming, err := fetchTeamSchedule(ctx, found.ID, true)
+	if err != nil {
+		return digestSection{}, err
+	}
+	section.Fixtures = fixturesBetween(upcoming, now, now.Add(period))
+	return section, nil
+}
+
+// fixturesBetween returns the unplayed events kicking off in [from, to),
+// soonest first
+func fixturesBetween(events []Event, from, to time.Time) []Event {
+	var fixtures []Event
+	for _, event := range events {
+		kickoff, err := parseEventTime(event.Date)
+		if err != nil || event.Status.Type.State != "pre" || kickoff.Before(from) || !kickoff.Before(to) {
+			continue
+		}
+		fixtures = append(fixtures, event)
+	}
+	sort.Slice(fixtures, func(i, j int) bool { return fixtures[i].Date < fixtures[j].Date })
+	return fixtures
+}
+
+func resultLine(r matchResult) string {
+	return fmt.Sprintf("%s %d-%d %s", r.Home.DisplayName, r.HomeGoals, r.AwayGoals, r.Away.DisplayName)
+}
+
+func fixtureLine(event Event) string {
+	when := event.Date
+	if kickoff, err := parseEventTime(event.Date); err == nil {
+		when = kickoff.Local().Format("Mon 2 Jan 15:04")
+	}
+	if len(event.Competitions) > 0 {
+		if home, away, ok := homeAndAway(event.Competitions[0]); ok {
+			return fmt.Sprintf("%s: %s vs %s", when, home.Team.DisplayName, away.Team.DisplayName)
+		}
+	}
+	return fmt.Sprintf("%s: %s", when, event.Name)
+}
+
+func movementLine(m tableMovement) string {
+	arrow := "▲"
+	if m.To > m.From {
+		arrow = "▼"
+	}
+	return fmt.Sprintf("%s %s: %d → %d (%d pts)", arrow, m.Team, m.From, m.To, m.Points)
+}
+
+// walk calls heading and item for every heading and line of the digest, and
+// empty where there is nothing to list, so the renderers only differ in markup
+func (d *digest) walk(heading func(level int, text string), item func(text string), empty func()) {
+	heading(1, d.Title)
+	if len(d.Sections) == 0 {
+		empty()
+	}
+	for _, s := range d.Sections {
+		heading(2, s.Name)
+		if len(s.Results) > 0 {
+			heading(3, "Results")
+			for _, r := range s.Results {
+				item(resultLine(r))
+			}
+		}
+		if len(s.Fixtures) > 0 {
+			heading(3, "Fixtures")
+			for _, f := range s.Fixtures {
+				item(fixtureLine(f))
+			}
+		}
+		if len(s.Movements) > 0 {
+			heading(3, "Table movements")
+			for _, m := range s.Movements {
+				item(movementLine(m))
+			}
+		}
+		if len(s.Results)+len(s.Fixtures)+len(s.Movements) == 0 {
+			empty()
+		}
+	}
+}
+
+func renderDigestMarkdown(d *digest) string {
+	var b strings.Builder
+	// Every block is preceded by a blank line
+	listing := false
+	d.walk(
+		func(level int, text string) {
+			fmt.Fprintf(&b, "\n%s %s\n", strings.Repeat("#", level), text)
+			listing = false
+		},
+		func(text string) {
+			if !listing {
+				b.WriteString("\n")
+				listing = true
+			}
+			fmt.Fprintf(&b, "- %s\n", text)
+		},
+		func() { b.WriteString("\nNothing to report.\n") },
+	)
+	return strings.TrimLeft(b.String(), "\n")
+}
+
+func renderDigestText(d *digest) string {
+	var b strings.Builder
+	d.walk(
+		func(level int, text string) {
+			switch level {
+			case 1:
+				fmt.Fprintf(&b, "%s\n%s\n", text, strings.Repeat("=", len(text)))
+			case 2:
+				fmt.Fprintf(&b, "\n%s\n%s\n", text, strings.Repeat("-", len(text)))
+			default:
+				fmt.Fprintf(&b, "\n%s:\n", text)
+			}
+		},
+		func(text string) { fmt.Fprintf(&b, "  %s\n", text) },
+		func() { b.WriteString("  Nothing to report.\n") },
+	)
+	return b.String()
+}
+
+func renderDigestHTML(d *digest) string {
+	var b strings.Builder
+	inList := false
+	closeList := func() {
+		if inList {
+			b.WriteString("</ul>\n")
+			inList = false
+		}
+	}
+
+	fmt.Fprintf(&b, "<!DOCTYPE html>\n<html>\n<head><meta charset=\"utf-8\"><title>%s</title></head>\n<body>\n", html.EscapeString(d.Title))
+	d.walk(
+		func(level int, text string) {
+			closeList()
+			fmt.Fprintf(&b, "<h%d>%s</h%d>\n", level, html.EscapeString(text), level)
+		},
+		func(text string) {
+			if !inList {
+				b.WriteString("<ul>\n")
+				inList = true
+			}
+			fmt.Fprintf(&b, "<li>%s</li>\n", html.EscapeString(text))
+		},
+		func() {
+			closeList()
+			b.WriteString("<p>Nothing to report.</p>\n")
+		},
+	)
+	closeList()
+	b.WriteString("</body>\n</html>\n")
+	return b.String()
+}
+
+// deliverDigest sends the rendered digest to every destination given, or
+// prints it when there are none
+func deliverDigest(ctx context.Context, subject, body string) error {
+	delivered := false
+
+	if digestOut != "" {
+		if err := os.WriteFile(digestOut, []byte(body), 0644); err != nil {
+			return err
+		}
+		fmt.Printf("Digest written to %s\n", digestOut)
+		delivered = true
+	}
+
+	if digestWebhook != "" {
+		payload := map[string]string{"subject": subject, "format": digestFormat, "body": body}
+		if err := postJSON(ctx, digestWebhook, payload); err != nil {
+			return err
+		}
+		delivered = true
+	}
+
+	if smtpServer != "" {
+		if err := emailDigest(subject, body); err != nil {
+			return err
+		}
+		fmt.Printf("Digest emailed to %s\n", strings.Join(smtpTo, ", "))
+		delivered = true
+	}
+
+	if !delivered {
+		fmt.Print(body)
+	}
+	return nil
+}
+
+// emailDigest sends the digest through the --smtp server, authenticating
+// when a password is set
+func emailDigest(subject, body string) error {
+	if smtpFrom == "" || len(smtpTo) == 0 {
+		return errInvalidInput("emailing a digest needs --from and --to")
+	}
+
+	contentType := "text/plain"
+	if digestFormat == "html" {
+		contentType = "text/html"
+	}
+
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", smtpFrom)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(smtpTo, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", subject)
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&msg, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Type: %s; charset=utf-8\r\n\r\n", contentType)
+	msg.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+
+	var auth smtp.Auth
+	if password := os.Getenv("SHARINGAN_SMTP_PASSWORD"); password != "" {
+		host := smtpServer
+		if i := strings.LastIndex(host, ":"); i >= 0 {
+			host = host[:i]
+		}
+		auth = smtp.PlainAuth("", defaultIfEmpty(smtpUser, smtpFrom), password, host)
+	}
+	return smtp.SendMail(smtpServer, auth, smtpFrom, smtpTo, []byte(msg.String()))
+}
@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose problems with the upstream API",
+}
+
+var doctorSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Check ESPN's payloads against the models sharingan expects",
+	Long: `The 'doctor schema' command fetches each ESPN endpoint sharingan uses and
+compares the payload with the model it is decoded into. For every endpoint
+it reports:
+
+  type changed  a field has a different JSON type than the model expects,
+                which usually makes decoding fail
+  missing       a field the model requires isn't in the payload
+  unknown       a field the model doesn't know about; harmless, but a
+                sign the payload changed
+
+The team and match endpoints are checked with the first team and match of
+the league. To check payloads as commands run, use --schema-check.
+
+Examples:
+  # Check the endpoints with Premier League data
+  sharingan doctor schema
+
+  # Check with La Liga data, as JSON
+  sharingan doctor schema --league esp.1 --format json
+
+  # Warn about drift while running another command
+  sharingan team --name Arsenal --schema-check
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runDoctorSchema(cmd.Context())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+	doctorCmd.AddCommand(doctorSchemaCmd)
+
+	doctorSchemaCmd.Flags().StringVarP(&league, "league", "l", "eng.1", "League whose data to check with")
+	doctorSchemaCmd.Flags().StringVarP(&format, "format", "f", "pretty", "Output format (pretty, json)")
+}
+
+// schemaReport is the outcome of checking one endpoint
+type schemaReport struct {
+	Endpoint string        `json:"endpoint"`
+	URL      string        `json:"url"`
+	Error    string        `json:"error,omitempty"`
+	Drift    []schemaDrift `json:"drift"`
+}
+
+func runDoctorSchema(ctx context.Context) error {
+	slug := strings.ToLower(defaultIfEmpty(league, "eng.1"))
+	var reports []schemaReport
+
+	// check fetches an endpoint straight from ESPN and compares it with
+	// model; the body is returned to find the IDs later endpoints need
+	check := func(name, url string, model interface{}) []byte {
+		report := schemaReport{Endpoint: name, URL: url, Drift: []schemaDrift{}}
+		body, err := fetchUpstream(ctx, url)
+		if err == nil {
+			var drift []schemaDrift
+			if drift, err = checkSchema(body, reflect.TypeOf(model)); err == nil {
+				report.Drift = drift
+			}
+		}
+		if err != nil {
+			report.Error = err.Error()
+			body = nil
+		}
+		reports = append(reports, report)
+		return body
+	}
+
+	var scoreboard ESPNResponse
+	body := check("league scoreboard", fmt.Sprintf("%s/%s/scoreboard", espnSoccerBase, slug), ESPNResponse{})
+	json.Unmarshal(body, &scoreboard)
+	check("all-leagues scoreboard", espnSoccerBase+"/all/scoreboard", ESPNResponse{})
+	check("standings", fmt.Sprintf("%s/%s/standings", espnStandingsBase, slug), providerStandings{})
+
+	var teams TeamsResponse
+	body = check("teams", fmt.Sprintf("%s/%s/teams", espnSoccerBase, slug), TeamsResponse{})
+	json.Unmarshal(body, &teams)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	teamID := ""
+	for _, sport := range teams.Sports {
+		for _, l := range sport.Leagues {
+			for _, t := range l.Teams {
+				teamID = defaultIfEmpty(teamID, t.Team.ID)
+			}
+		}
+	}
+	eventID := ""
+	for _, event := range scoreboard.Events {
+		eventID = defaultIfEmpty(eventID, event.ID)
+	}
+
+	if teamID != "" {
+		check("team", fmt.Sprintf("%s/all/teams/%s", espnSoccerBase, teamID), teamDetail{})
+		var schedule ESPNResponse
+		body = check("team schedule", fmt.Sprintf("%s/all/teams/%s/schedule", espnSoccerBase, teamID), ESPNResponse{})
+		json.Unmarshal(body, &schedule)
+		for _, event := range schedule.Events {
+			eventID = defaultIfEmpty(eventID, event.ID)
+		}
+	}
+	if eventID != "" {
+		check("match summary", fmt.Sprintf("%s/%s/summary?event=%s", espnSoccerBase, slug, eventID), matchSummary{})
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	if format == "json" {
+		out, _ := json.MarshalIndent(reports, "", "  ")
+		fmt.Println(string(out))
+		return nil
+	}
+	displaySchemaReports(slug, reports)
+	return nil
+}
+
+func displaySchemaReports(slug string, reports []schemaReport) {
+	header := color.New(color.FgCyan, color.Bold).SprintFunc()
+	bad := color.New(color.FgRed, color.Bold).SprintFunc()
+	warn := color.New(color.FgYellow).SprintFunc()
+	good := color.New(color.FgGreen).SprintFunc()
+
+	fmt.Printf("\n%s\n", header("SCHEMA CHECK ("+slug+")"))
+	fmt.Println("=================================")
+
+	totals := make(map[string]int)
+	for _, r := range reports {
+		if r.Error != "" {
+			fmt.Printf("%-24s %s %s\n", r.Endpoint, bad("ERROR"), r.Error)
+			continue
+		}
+
+		counts := make(map[string]int)
+		// Unknown fields are listed by the object they appear in
+		unknown := make(map[string][]string)
+		for _, d := range r.Drift {
+			counts[d.Kind]++
+			totals[d.Kind]++
+			if d.Kind == "unknown" {
+				parent, name := "", d.Path
+				if i := strings.LastIndex(d.Path, "."); i >= 0 {
+					parent, name = d.Path[:i], d.Path[i+1:]
+				}
+				unknown[parent] = append(unknown[parent], name)
+			}
+		}
+
+		status := good("ok")
+		if counts["type_changed"]+counts["missing"] > 0 {
+			status = bad("DRIFT")
+		}
+		fmt.Printf("%-24s %s (%d type changed, %d missing, %d unknown)\n", r.Endpoint, status,
+			counts["type_changed"], counts["missing"], counts["unknown"])
+
+		for _, d := range r.Drift {
+			switch d.Kind {
+			case "type_changed":
+				fmt.Printf("  %s  %s: expected %s, got %s (%dx)\n", bad("TYPE CHANGED"), d.Path, d.Expected, d.Actual, d.Count)
+			case "missing":
+				fmt.Printf("  %s       %s: expected %s (%dx)\n", warn("MISSING"), d.Path, d.Expected, d.Count)
+			}
+		}
+		parents := make([]string, 0, len(unknown))
+		for parent := range unknown {
+			parents = append(parents, parent)
+		}
+		sort.Strings(parents)
+		for _, parent := range parents {
+			fmt.Printf("  UNKNOWN       %s: %s\n", defaultIfEmpty(parent, "(top level)"), strings.Join(unknown[parent], ", "))
+		}
+	}
+
+	fmt.Printf("\nChecked %d endpoints: %d type changed, %d missing, %d unknown fields\n",
+		len(reports), totals["type_changed"], totals["missing"], totals["unknown"])
+}
@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// competitionTier groups competitions that share a K-factor
+type competitionTier int
+
+const (
+	tierLeague competitionTier = iota
+	tierCup
+	tierContinental
+	tierFriendly
+)
+
+func (t competitionTier) String() string {
+	switch t {
+	case tierLeague:
+		return "league"
+	case tierCup:
+		return "cup"
+	case tierContinental:
+		return "continental"
+	default:
+		return "friendly"
+	}
+}
+
+var domesticLeagueSlug = regexp.MustCompile(`^[a-z]{3}\.\d+$`)
+
+// tierForLeague classifies an ESPN league slug (eng.1, eng.fa, uefa.champions, ...)
+func tierForLeague(slug string) competitionTier {
+	slug = strings.ToLower(slug)
+	switch {
+	case strings.Contains(slug, "friendly"):
+		return tierFriendly
+	case domesticLeagueSlug.MatchString(slug):
+		return tierLeague
+	case strings.HasPrefix(slug, "uefa."), strings.HasPrefix(slug, "conmebol."),
+		strings.HasPrefix(slug, "concacaf."), strings.HasPrefix(slug, "afc."),
+		strings.HasPrefix(slug, "caf."), strings.HasPrefix(slug, "fifa."):
+		return tierContinental
+	default:
+		return tierCup
+	}
+}
+
+// eloConfig holds the parameters of the rating model
+type eloConfig struct {
+	Initial       float64
+	HomeAdvantage float64
+	KFactors      map[competitionTier]float64
+}
+
+// defaultEloConfig follows the World Football Elo conventions, scaled for club football
+var defaultEloConfig = eloConfig{
+	Initial:       1500,
+	HomeAdvantage: 65,
+	KFactors: map[competitionTier]float64{
+		tierLeague:      20,
+		tierCup:         25,
+		tierContinental: 30,
+		tierFriendly:    10,
+	},
+}
+
+// eloPoint is a team's rating after one match
+type eloPoint struct {
+	Date    time.Time `json:"date"`
+	EventID string    `json:"eventId"`
+	Rating  float64   `json:"rating"`
+	Delta   float64   `json:"delta"`
+}
+
+// eloRating is the current rating of a team and how it got there
+type eloRating struct {
+	Team    Team       `json:"team"`
+	Rating  float64    `json:"rating"`
+	History []eloPoint `json:"history"`
+}
+
+// change returns the rating movement over the team's last n matches
+func (r *eloRating) change(n int) float64 {
+	var total float64
+	for i := len(r.History) - 1; i >= 0 && i >= len(r.History)-n; i-- {
+		total += r.History[i].Delta
+	}
+	return total
+}
+
+// eloTable accumulates ratings as results are applied in chronological order
+type eloTable struct {
+	cfg     eloConfig
+	ratings map[string]*eloRating
+}
+
+func newEloTable(cfg eloConfig) *eloTable {
+	return &eloTable{cfg: cfg, ratings: make(map[string]*eloRating)}
+}
+
+func (t *eloTable) get(team Team) *eloRating {
+	r, ok := t.ratings[team.ID]
+	if !ok {
+		r = &eloRating{Team: team, Rating: t.cfg.Initial}
+		t.ratings[team.ID] = r
+	}
+	return r
+}
+
+// expectedScore is the home side's expected result (1 win, 0.5 draw, 0 loss)
+func (t *eloTable) expectedScore(home, away float64) float64 {
+	return 1 / (1 + math.Pow(10, (away-(home+t.cfg.HomeAdvantage))/400))
+}
+
+// goalDifferenceMultiplier scales the rating change by the margin of victory
+func goalDifferenceMultiplier(diff int) float64 {
+	if diff < 0 {
+		diff = -diff
+	}
+	switch {
+	case diff <= 1:
+		return 1
+	case diff == 2:
+		return 1.5
+	default:
+		return (11 + float64(diff)) / 8
+	}
+}
+
+// apply updates both teams' ratings with a result
+func (t *eloTable) apply(m matchResult) {
+	home, away := t.get(m.Home), t.get(m.Away)
+
+	actual := 0.5
+	switch {
+	case m.HomeGoals > m.AwayGoals:
+		actual = 1
+	case m.HomeGoals < m.AwayGoals:
+		actual = 0
+	}
+
+	k := t.cfg.KFactors[tierForLeague(m.League)]
+	delta := k * goalDifferenceMultiplier(m.HomeGoals-m.AwayGoals) * (actual - t.expectedScore(home.Rating, away.Rating))
+
+	home.Rating += delta
+	away.Rating -= delta
+	home.History = append(home.History, eloPoint{Date: m.Date, EventID: m.EventID, Rating: home.Rating, Delta: delta})
+	away.History = append(away.History, eloPoint{Date: m.Date, EventID: m.EventID, Rating: away.Rating, Delta: -delta})
+}
+
+// ranked returns the ratings sorted from strongest to weakest
+func (t *eloTable) ranked() []*eloRating {
+	list := make([]*eloRating, 0, len(t.ratings))
+	for _, r := range t.ratings {
+		list = append(list, r)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Rating != list[j].Rating {
+			return list[i].Rating > list[j].Rating
+		}
+		return list[i].Team.DisplayName < list[j].Team.DisplayName
+	})
+	return list
+}
+
+// buildEloTable applies every result played up to asOf
+func buildEloTable(cfg eloConfig, results []matchResult, asOf time.Time) *eloTable {
+	sortResults(results)
+	table := newEloTable(cfg)
+	for _, m := range results {
+		if m.Date.After(asOf) {
+			break
+		}
+		table.apply(m)
+	}
+	return table
+}
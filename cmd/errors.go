@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Exit codes, documented in the root command's help
+const (
+	exitOK           = 0
+	exitFailure      = 1 // anything not covered below
+	exitInvalidInput = 2
+	exitNotFound     = 3
+	exitNetwork      = 4
+	exitUpstreamHTTP = 5
+	exitDecode       = 6
+	exitTimeout      = 7
+	exitInterrupted  = 130 // as shells report a command killed by Ctrl-C
+)
+
+// networkError means the upstream API couldn't be reached at all
+type networkError struct {
+	URL string
+	Err error
+}
+
+func (e *networkError) Error() string { return fmt.Sprintf("fetching %s: %v", e.URL, e.Err) }
+func (e *networkError) Unwrap() error { return e.Err }
+
+// httpError means the upstream API answered with a non-2xx status, or with
+// ESPN's error envelope in place of the data
+type httpError struct {
+	URL        string
+	StatusCode int
+	Status     string
+	Message    string // ESPN's error message, if it sent one
+}
+
+func (e *httpError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("fetching %s: %s: %s", e.URL, e.Status, e.Message)
+	}
+	return fmt.Sprintf("fetching %s: %s", e.URL, e.Status)
+}
+
+// retryable reports whether the same request may succeed later: timeouts,
+// rate limiting and server-side failures are, bad requests and missing
+// resources aren't
+func (e *httpError) retryable() bool {
+	switch e.StatusCode {
+	case http.StatusRequestTimeout, http.StatusTooEarly, http.StatusTooManyRequests:
+		return true
+	}
+	return e.StatusCode >= 500
+}
+
+// decodeError means a response couldn't be parsed
+type decodeError struct {
+	What string
+	Err  error
+}
+
+func (e *decodeError) Error() string { return fmt.Sprintf("parsing %s: %v", e.What, e.Err) }
+func (e *decodeError) Unwrap() error { return e.Err }
+
+// timeoutError means the command ran past --timeout
+type timeoutError struct {
+	After time.Duration
+	Err   error
+}
+
+func (e *timeoutError) Error() string { return fmt.Sprintf("timed out after %s: %v", e.After, e.Err) }
+func (e *timeoutError) Unwrap() error { return e.Err }
+
+// notFoundError means the requested team, match, league, ... doesn't exist
+type notFoundError struct {
+	Msg string
+}
+
+func (e *notFoundError) Error() string { return e.Msg }
+
+// inputError means the user asked for something invalid
+type inputError struct {
+	Msg string
+}
+
+func (e *inputError) Error() string { return e.Msg }
+
+func errNotFound(format string, args ...interface{}) error {
+	return &notFoundError{fmt.Sprintf(format, args...)}
+}
+
+func errInvalidInput(format string, args ...interface{}) error {
+	return &inputError{fmt.Sprintf(format, args...)}
+}
+
+// errorKind classifies an error for --quiet output and the exit code
+func errorKind(err error) (string, int) {
+	var (
+		network  *networkError
+		upstream *httpError
+		decode   *decodeError
+		notFound *notFoundError
+		input    *inputError
+		timeout  *timeoutError
+	)
+	switch {
+	case err == nil:
+		return "", exitOK
+	case errors.As(err, &timeout):
+		return "timeout", exitTimeout
+	case errors.Is(err, context.Canceled):
+		return "interrupted", exitInterrupted
+	case errors.As(err, &input):
+		return "invalid_input", exitInvalidInput
+	case errors.As(err, &notFound):
+		return "not_found", exitNotFound
+	case errors.As(err, &upstream):
+		return "upstream_http", exitUpstreamHTTP
+	case errors.As(err, &network):
+		return "network", exitNetwork
+	case errors.As(err, &decode):
+		return "decode", exitDecode
+	}
+	return "error", exitFailure
+}
+
+// isRetryable reports whether err is a transient upstream failure worth
+// retrying: network errors and retryable HTTP statuses
+func isRetryable(err error) bool {
+	var (
+		network  *networkError
+		upstream *httpError
+	)
+	switch {
+	case errors.As(err, &upstream):
+		return upstream.retryable()
+	case errors.As(err, &network):
+		return true
+	}
+	return false
+}
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// espnSoccerBase is the root of ESPN's public soccer site API
+const espnSoccerBase = "https://site.api.espn.com/apis/site/v2/sports/soccer"
+
+// fetchESPN performs a GET request against the ESPN API and returns the raw
+// body, falling back to a mirror or archived copy while ESPN is failing
+func fetchESPN(ctx context.Context, url string) ([]byte, error) {
+	return fetchWithFailover(ctx, url)
+}
+
+// fetchUpstream performs a GET request and returns the raw body
+func fetchUpstream(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, errInvalidInput("creating request: %v", err)
+	}
+
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/114.0.0.0 Safari/537.36")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Accept-Language", "en-US,en;q=0.9")
+
+	resp, err := espnClient().Do(req)
+	if err != nil {
+		return nil, &networkError{URL: url, Err: err}
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &networkError{URL: url, Err: err}
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		code, message, _ := parseESPNError(body)
+		if code == 0 {
+			code = resp.StatusCode
+		}
+		return nil, &httpError{URL: url, StatusCode: code, Status: resp.Status, Message: message}
+	}
+	// Some endpoints answer 200 with an error envelope instead of data
+	if code, message, ok := parseESPNError(body); ok {
+		return nil, &httpError{URL: url, StatusCode: code, Status: fmt.Sprintf("%d %s", code, http.StatusText(code)), Message: message}
+	}
+	return body, nil
+}
+
+// espnErrorEnvelope is the body ESPN sends when a request fails, e.g.
+// {"code":400,"message":"Failed to get events endpoint."}. Some endpoints
+// nest it under "error".
+type espnErrorEnvelope struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Error   *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// parseESPNError extracts the status code and message of an ESPN error
+// envelope. ok is false when the body isn't one.
+func parseESPNError(body []byte) (code int, message string, ok bool) {
+	var envelope espnErrorEnvelope
+	if json.Unmarshal(body, &envelope) != nil {
+		return 0, "", false
+	}
+	if e := envelope.Error; e != nil && e.Code != 0 {
+		return e.Code, e.Message, true
+	}
+	if envelope.Code != 0 && envelope.Message != "" {
+		return envelope.Code, envelope.Message, true
+	}
+	return 0, "", false
+}
+
+// fetchMatchSummary retrieves the summary of a match
+func fetchMatchSummary(ctx context.Context, leagueSlug, matchID string) (matchSummary, error) {
+	var summary matchSummary
+	body, err := fetchESPN(ctx, fmt.Sprintf("%s/%s/summary?event=%s", espnSoccerBase, defaultIfEmpty(leagueSlug, "all"), matchID))
+	if err != nil {
+		return summary, err
+	}
+	if err := decodeESPN("match summary", body, &summary); err != nil {
+		return summary, err
+	}
+	return summary, nil
+}
+
+// parseEventTime parses the timestamps ESPN uses for events. They usually
+// omit seconds ("2024-03-20T15:00Z"), which time.RFC3339 rejects.
+func parseEventTime(value string) (time.Time, error) {
+	for _, layout := range []string{"2006-01-02T15:04Z07:00", time.RFC3339} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid event time %q", value)
+}
+
+// homeAndAway returns the home and away competitors of a competition
+func homeAndAway(c Competition) (home, away Competitor, ok bool) {
+	if len(c.Competitors) < 2 {
+		return home, away, false
+	}
+	home, away = c.Competitors[0], c.Competitors[1]
+	if home.HomeAway == "away" || away.HomeAway == "home" {
+		home, away = away, home
+	}
+	return home, away, true
+}
+
+// eventLeagueSlug returns the league slug of an event, falling back to the
+// --league flag when it is a slug such as eng.1
+func eventLeagueSlug(e Event) string {
+	if e.League.Slug != "" {
+		return e.League.Slug
+	}
+	if isLeagueSlug(league) {
+		return strings.ToLower(strings.TrimSpace(league))
+	}
+	return ""
+}
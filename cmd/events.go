@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Match event types produced by the detector
+const (
+	eventKickoff  = "kickoff"
+	eventGoal     = "goal"
+	eventRedCard  = "red_card"
+	eventHalfTime = "half_time"
+	eventFullTime = "full_time"
+	eventStatus   = "status"
+	eventReminder = "reminder"
+)
+
+// matchEvent is something that happened in a match between two polls
+type matchEvent struct {
+	// ID identifies the event across polls and restarts
+	ID     string    `json:"id"`
+	Type   string    `json:"type"`
+	Match  apiMatch  `json:"match"`
+	TeamID string    `json:"teamId,omitempty"`
+	Time   time.Time `json:"time"`
+}
+
+// String renders the event as a one-line message
+func (e matchEvent) String() string {
+	m := e.Match
+	score := fmt.Sprintf("%s %s-%s %s", m.Home.Name, scoreText(m.Home.Score), scoreText(m.Away.Score), m.Away.Name)
+
+	switch e.Type {
+	case eventKickoff:
+		return fmt.Sprintf("Kick-off: %s vs %s", m.Home.Name, m.Away.Name)
+	case eventGoal:
+		scorer := m.Home.Name
+		if e.TeamID == m.Away.ID {
+			scorer = m.Away.Name
+		}
+		return fmt.Sprintf("GOAL %s! %s (%s)", scorer, score, defaultIfEmpty(m.Clock, m.Status))
+	case eventRedCard:
+		player := m.Home.Name
+		if e.TeamID == m.Away.ID {
+			player = m.Away.Name
+		}
+		return fmt.Sprintf("Red card for %s: %s (%s)", player, score, defaultIfEmpty(m.Clock, m.Status))
+	case eventHalfTime:
+		return fmt.Sprintf("Half-time: %s", score)
+	case eventFullTime:
+		return fmt.Sprintf("Full-time: %s", score)
+	case eventReminder:
+		msg := fmt.Sprintf("Reminder: %s vs %s", m.Home.Name, m.Away.Name)
+		if kickoff, err := time.Parse(time.RFC3339, m.Kickoff); err == nil {
+			msg += fmt.Sprintf(" kicks off in %s (%s)", formatLead(kickoff.Sub(e.Time)), kickoff.Local().Format("15:04"))
+		}
+		var where []string
+		for _, s := range []string{m.LeagueName, m.Venue} {
+			if s != "" {
+				where = append(where, s)
+			}
+		}
+		if len(where) > 0 {
+			msg += " - " + strings.Join(where, ", ")
+		}
+		return msg
+	default:
+		return fmt.Sprintf("%s: %s", m.Status, score)
+	}
+}
+
+// formatLead renders a time until kick-off in minutes, e.g. "1h", "1h30m", "15m"
+func formatLead(d time.Duration) string {
+	d = d.Round(time.Minute)
+	hours, minutes := int(d.Hours()), int(d.Minutes())%60
+	switch {
+	case hours == 0:
+		return fmt.Sprintf("%dm", minutes)
+	case minutes == 0:
+		return fmt.Sprintf("%dh", hours)
+	default:
+		return fmt.Sprintf("%dh%dm", hours, minutes)
+	}
+}
+
+func scoreText(score *int) string {
+	if score == nil {
+		return "-"
+	}
+	return fmt.Sprint(*score)
+}
+
+// involves reports whether the event's match matches a team filter (ID, name
+// or abbreviation)
+func (e matchEvent) involves(teamFilter string) bool {
+	if teamFilter == "" {
+		return true
+	}
+	for _, side := range []apiSide{e.Match.Home, e.Match.Away} {
+		if side.ID == teamFilter || strings.EqualFold(side.Abbreviation, teamFilter) ||
+			strings.Contains(strings.ToLower(side.Name), strings.ToLower(teamFilter)) {
+			return true
+		}
+	}
+	return false
+}
+
+// eventDetector turns successive scoreboard snapshots into match events. It
+// only remembers the matches of the latest snapshot.
+type eventDetector struct {
+	last   map[string]apiMatch
+	primed bool
+}
+
+func newEventDetector() *eventDetector {
+	return &eventDetector{last: make(map[string]apiMatch)}
+}
+
+// update compares a scoreboard snapshot with the previous one. The first
+// snapshot only records the current state, so starting the detector doesn't
+// replay everything that already happened today.
+func (d *eventDetector) update(events []Event) []matchEvent {
+	now := time.Now().UTC()
+	var detected []matchEvent
+
+	next := make(map[string]apiMatch, len(events))
+	for _, event := range events {
+		current := normalizeMatch(event)
+		previous, seen := d.last[current.ID]
+		next[current.ID] = current
+		if !d.primed || !seen {
+			continue
+		}
+		detected = append(detected, diffMatch(previous, current, now)...)
+	}
+
+	d.last = next
+	d.primed = true
+	return detected
+}
+
+// diffMatch lists the events that explain the change from previous to current
+func diffMatch(previous, current apiMatch, now time.Time) []matchEvent {
+	var detected []matchEvent
+	add := func(kind, teamID, key string) {
+		detected = append(detected, matchEvent{
+			ID:     fmt.Sprintf("%s:%s:%s", current.ID, kind, key),
+			Type:   kind,
+			Match:  current,
+			TeamID: teamID,
+			Time:   now,
+		})
+	}
+
+	if previous.State == "pre" && current.State == "in" {
+		add(eventKickoff, "", "")
+	}
+
+	for _, side := range []struct{ before, after apiSide }{
+		{previous.Home, current.Home},
+		{previous.Away, current.Away},
+	} {
+		before, after := scoreValue(side.before.Score), scoreValue(side.after.Score)
+		for goal := before + 1; goal <= after; goal++ {
+			add(eventGoal, side.after.ID, fmt.Sprintf("%s:%d", side.after.ID, goal))
+		}
+		for card := side.before.RedCards + 1; card <= side.after.RedCards; card++ {
+			add(eventRedCard, side.after.ID, fmt.Sprintf("%s:%d", side.after.ID, card))
+		}
+	}
+
+	// The detail text ticks with the clock during play, so only a change of
+	// status (first half, half-time, full-time, ...) counts
+	switch {
+	case previous.StatusName == current.StatusName || previous.State == "pre" && current.State == "in":
+	case current.StatusName == "STATUS_HALFTIME":
+		add(eventHalfTime, "", "")
+	case previous.State == "in" && current.State == "post":
+		add(eventFullTime, "", "")
+	default:
+		add(eventStatus, "", current.StatusName)
+	}
+
+	return detected
+}
+
+func scoreValue(score *int) int {
+	if score == nil {
+		return 0
+	}
+	return *score
+}
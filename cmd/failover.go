@@ -0,0 +1,335 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// When ESPN keeps failing, a circuit breaker stops sending it requests for a
+// while. Requests it can't answer go to a fallback mirror, if one is
+// configured, and then to the last archived copy of the same response. Data
+// from either is reported with a banner so nobody mistakes it for live data.
+
+// espnHost is the part of every upstream URL a fallback mirror replaces
+const espnHost = "https://site.api.espn.com"
+
+const (
+	breakerThreshold = 5                // consecutive failures that open the circuit
+	breakerCooldown  = 30 * time.Second // how long it stays open before a probe
+	archiveMaxAge    = 7 * 24 * time.Hour
+	archiveMaxBytes  = 50 << 20 // the oldest copies go first beyond this
+)
+
+// fallbackURL is the --fallback flag; it overrides the config's "fallback"
+var fallbackURL string
+
+// errCircuitOpen is returned, wrapped in a networkError, for requests not
+// sent because the circuit is open and no fallback could answer them
+var errCircuitOpen = errors.New("ESPN is failing, requests paused")
+
+// espnBreaker guards every request to ESPN
+var espnBreaker = &circuitBreaker{threshold: breakerThreshold, cooldown: breakerCooldown}
+
+// circuitBreaker opens after threshold consecutive failures. Once the
+// cooldown has passed it lets a single probe through: success closes the
+// circuit, failure opens it again.
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	failures int
+	open     bool
+	openedAt time.Time
+	probing  bool
+}
+
+// allow reports whether a request may be sent
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.open {
+		return true
+	}
+	if b.probing || time.Since(b.openedAt) < b.cooldown {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+func (b *circuitBreaker) success() {
+	b.mu.Lock()
+	b.failures, b.open, b.probing = 0, false, false
+	b.mu.Unlock()
+}
+
+func (b *circuitBreaker) failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	if b.probing || b.failures >= b.threshold {
+		b.open, b.openedAt, b.probing = true, time.Now(), false
+	}
+}
+
+// abandon gives up a request that ended without telling whether ESPN is
+// up, so another probe may be sent
+func (b *circuitBreaker) abandon() {
+	b.mu.Lock()
+	b.probing = false
+	b.mu.Unlock()
+}
+
+// isOpen reports whether requests are currently being diverted
+func (b *circuitBreaker) isOpen() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.open
+}
+
+// fetchWithFailover fetches url from ESPN, or from a fallback when ESPN is
+// failing. Errors that show ESPN is up, such as a 404, are returned as is.
+func fetchWithFailover(ctx context.Context, url string) ([]byte, error) {
+	primaryErr := error(&networkError{URL: url, Err: errCircuitOpen})
+	if espnBreaker.allow() {
+		body, err := fetchUpstream(ctx, url)
+		if ctx.Err() != nil {
+			// Our own deadline or cancellation says nothing about ESPN
+			espnBreaker.abandon()
+			return nil, err
+		}
+		if err == nil || !isRetryable(err) {
+			espnBreaker.success()
+			if err == nil {
+				archiveResponse(url, body)
+			}
+			return body, err
+		}
+		espnBreaker.failure()
+		primaryErr = err
+		slog.Info("ESPN request failed, trying fallbacks", "url", url, "err", err)
+	}
+
+	if mirror := mirrorURL(url); mirror != "" {
+		body, err := fetchUpstream(ctx, mirror)
+		if err == nil {
+			noteFallback(hostOf(mirror), time.Time{})
+			markFallback(ctx)
+			archiveResponse(url, body)
+			return body, nil
+		}
+	}
+
+	if body, fetched, ok := archivedResponse(url); ok {
+		noteFallback("archived copy", fetched)
+		markFallback(ctx)
+		return body, nil
+	}
+	return nil, primaryErr
+}
+
+var (
+	fallbackBaseOnce sync.Once
+	fallbackBase     string
+)
+
+// mirrorURL rewrites an ESPN URL to the fallback mirror, or returns "" when
+// there is none
+func mirrorURL(rawURL string) string {
+	fallbackBaseOnce.Do(func() {
+		fallbackBase = fallbackURL
+		if fallbackBase == "" {
+			if cfg, err := loadConfig(); err == nil {
+				fallbackBase = cfg.Fallback
+			}
+		}
+		fallbackBase = strings.TrimSuffix(fallbackBase, "/")
+	})
+	if fallbackBase == "" || !strings.HasPrefix(rawURL, espnHost) {
+		return ""
+	}
+	return fallbackBase + strings.TrimPrefix(rawURL, espnHost)
+}
+
+func hostOf(rawURL string) string {
+	if u, err := url.Parse(rawURL); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return rawURL
+}
+
+// archivePath is where the last good response for a URL is kept
+func archivePath(url string) (string, error) {
+	h := fnv.New64a()
+	h.Write([]byte(url))
+	return statePath(filepath.Join("archive", fmt.Sprintf("%016x.json", h.Sum64())))
+}
+
+var pruneArchiveOnce sync.Once
+
+// archiveResponse keeps a response so it can stand in for ESPN later.
+// Failures only cost the fallback, so they are ignored.
+func archiveResponse(url string, body []byte) {
+	path, err := archivePath(url)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return
+	}
+	pruneArchiveOnce.Do(func() { pruneArchive(filepath.Dir(path)) })
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, body, 0644); err != nil {
+		return
+	}
+	os.Rename(tmp, path)
+}
+
+// pruneArchive deletes archived copies too old to be served, then the
+// oldest of the rest until the archive fits in archiveMaxBytes
+func pruneArchive(dir string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	type archived struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+	var kept []archived
+	var total int64
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		if time.Since(info.ModTime()) > archiveMaxAge {
+			os.Remove(path)
+			continue
+		}
+		kept = append(kept, archived{path, info.Size(), info.ModTime()})
+		total += info.Size()
+	}
+
+	sort.Slice(kept, func(i, j int) bool { return kept[i].modTime.Before(kept[j].modTime) })
+	for _, a := range kept {
+		if total <= archiveMaxBytes {
+			break
+		}
+		if os.Remove(a.path) == nil {
+			total -= a.size
+		}
+	}
+}
+
+// archivedResponse returns the archived response for a URL and when it was
+// fetched, unless it is too old to be useful
+func archivedResponse(url string) ([]byte, time.Time, bool) {
+	path, err := archivePath(url)
+	if err != nil {
+		return nil, time.Time{}, false
+	}
+	info, err := os.Stat(path)
+	if err != nil || time.Since(info.ModTime()) > archiveMaxAge {
+		return nil, time.Time{}, false
+	}
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return nil, time.Time{}, false
+	}
+	return body, info.ModTime(), true
+}
+
+// fallbackNotice collects the fallback sources used since it was last taken
+var fallbackNotice struct {
+	mu      sync.Mutex
+	sources []string
+	oldest  time.Time // oldest archived copy used, if any
+}
+
+// noteFallback records that a response came from source; fetched is when an
+// archived copy was fetched, or zero for live data from a mirror
+func noteFallback(source string, fetched time.Time) {
+	fallbackNotice.mu.Lock()
+	defer fallbackNotice.mu.Unlock()
+	found := false
+	for _, s := range fallbackNotice.sources {
+		found = found || s == source
+	}
+	if !found {
+		fallbackNotice.sources = append(fallbackNotice.sources, source)
+	}
+	if !fetched.IsZero() && (fallbackNotice.oldest.IsZero() || fetched.Before(fallbackNotice.oldest)) {
+		fallbackNotice.oldest = fetched
+	}
+}
+
+// fetchSource records whether any response fetched with a context came from
+// a fallback rather than ESPN, e.g. to tag one API response
+type fetchSource struct {
+	fallback atomic.Bool
+}
+
+type fetchSourceKey struct{}
+
+// withFetchSource returns a context whose fallback responses are recorded
+// in src
+func withFetchSource(ctx context.Context, src *fetchSource) context.Context {
+	return context.WithValue(ctx, fetchSourceKey{}, src)
+}
+
+// markFallback flags the fetch source of ctx, if it has one
+func markFallback(ctx context.Context) {
+	if src, ok := ctx.Value(fetchSourceKey{}).(*fetchSource); ok {
+		src.fallback.Store(true)
+	}
+}
+
+// takeFallbackNotice returns a one-line description of the fallback data
+// served since the last call, or "" if everything came from ESPN
+func takeFallbackNotice() string {
+	fallbackNotice.mu.Lock()
+	defer fallbackNotice.mu.Unlock()
+	if len(fallbackNotice.sources) == 0 {
+		return ""
+	}
+	notice := "Data from fallback source (" + strings.Join(fallbackNotice.sources, ", ") + ")"
+	if !fallbackNotice.oldest.IsZero() {
+		notice += ", stale as of " + fallbackNotice.oldest.Local().Format("2006-01-02 15:04")
+	}
+	notice += ": ESPN is unavailable"
+	fallbackNotice.sources, fallbackNotice.oldest = nil, time.Time{}
+	return notice
+}
+
+// printFallbackBanner shows the fallback notice, if any. It always goes to
+// stderr, so it never ends up in JSON, calendars or other piped output.
+func printFallbackBanner() {
+	notice := takeFallbackNotice()
+	if notice == "" {
+		return
+	}
+	if format == "json" || quiet {
+		fmt.Fprintln(os.Stderr, "Warning: "+notice)
+		return
+	}
+	banner := color.New(color.FgBlack, color.BgYellow, color.Bold).SprintFunc()
+	fmt.Fprintln(os.Stderr, banner(" ⚠ "+notice+" "))
+}
@@ -0,0 +1,254 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// Grid tiles never get narrower than this, so team names stay readable
+const (
+	minTileWidth = 30
+	tileHeight   = 6
+
+	// goalFlash is how long a tile flashes after a goal
+	goalFlash = 10 * time.Second
+)
+
+// gridView is the state of `live --grid` between refreshes
+type gridView struct {
+	events   []Event
+	detector *eventDetector
+	flashing map[string]time.Time // match ID -> end of its goal flash
+	err      error
+	updated  time.Time
+	notice   string // set while data comes from a fallback source
+}
+
+// runGrid shows matches as tiles sized to the terminal until interrupted
+func runGrid(ctx context.Context) {
+	fmt.Print("\x1b[?1049h\x1b[?25l")
+	defer fmt.Print("\x1b[?25h\x1b[?1049l")
+
+	resize := make(chan os.Signal, 1)
+	notifyResize(resize)
+
+	view := &gridView{detector: newEventDetector(), flashing: make(map[string]time.Time)}
+	view.refresh(ctx)
+
+	poll := time.NewTicker(refreshInterval)
+	defer poll.Stop()
+	// Redraw often enough to animate goal flashes
+	frame := time.NewTicker(500 * time.Millisecond)
+	defer frame.Stop()
+
+	for {
+		view.draw()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-poll.C:
+			view.refresh(ctx)
+		case <-resize:
+			fmt.Print("\x1b[2J")
+		case <-frame.C:
+		}
+	}
+}
+
+// refresh fetches the scoreboard and starts a flash on every tile whose
+// score changed
+func (g *gridView) refresh(ctx context.Context) {
+	events, err := fetchTodayEvents(ctx)
+	g.err = err
+	if err != nil {
+		return
+	}
+	g.notice = takeFallbackNotice()
+
+	var filtered []Event
+	for _, event := range events {
+		if eventMatchesLeague(event, league) || isPinned(event.ID) {
+			filtered = append(filtered, event)
+		}
+	}
+	g.events = filtered
+	g.updated = time.Now()
+
+	for _, e := range g.detector.update(filtered) {
+		if e.Type == eventGoal {
+			g.flashing[e.Match.ID] = time.Now().Add(goalFlash)
+		}
+	}
+}
+
+func isPinned(id string) bool {
+	for _, pinned := range pinnedMatches {
+		if pinned == id {
+			return true
+		}
+	}
+	return false
+}
+
+// tiles picks the matches to show: pinned matches in the order given, then
+// live matches, or today's upcoming matches when nothing is being played
+func (g *gridView) tiles() []Event {
+	byID := make(map[string]Event, len(g.events))
+	for _, event := range g.events {
+		byID[event.ID] = event
+	}
+
+	var tiles []Event
+	for _, id := range pinnedMatches {
+		if event, ok := byID[id]; ok {
+			tiles = append(tiles, event)
+		}
+	}
+
+	live, upcoming, _ := groupMatchesByState(g.events)
+	if len(live) == 0 {
+		live = upcoming
+	}
+	for _, event := range live {
+		if !isPinned(event.ID) {
+			tiles = append(tiles, event)
+		}
+	}
+	return tiles
+}
+
+// draw renders the tiles in as many columns and rows as the terminal fits
+func (g *gridView) draw() {
+	width, height, err := terminalSize(int(os.Stdout.Fd()))
+	if err != nil {
+		width, height = 80, 24
+	}
+
+	columns := max(1, width/minTileWidth)
+	tileWidth := width / columns
+	rows := max(1, (height-2)/tileHeight)
+
+	tiles := g.tiles()
+	shown := min(len(tiles), columns*rows)
+
+	var screen strings.Builder
+	screen.WriteString("\x1b[H")
+
+	header := fmt.Sprintf(" %d matches", len(tiles))
+	if !g.updated.IsZero() {
+		header += " - updated " + g.updated.Format("15:04:05")
+	}
+	if g.err != nil {
+		header += fmt.Sprintf(" - refresh failed: %v", g.err)
+	}
+	headerStyle := color.New(color.Bold)
+	if g.notice != "" {
+		header += " - " + g.notice
+		headerStyle = color.New(color.FgBlack, color.BgYellow, color.Bold)
+	}
+	screen.WriteString(headerStyle.Sprint(padRight(header, width)))
+	screen.WriteString("\n")
+
+	now := time.Now()
+	for start := 0; start < shown; start += columns {
+		lines := make([]string, tileHeight)
+		for i := start; i < min(start+columns, shown); i++ {
+			for l, line := range g.tile(tiles[i], tileWidth, now) {
+				lines[l] += line
+			}
+		}
+		for _, line := range lines {
+			screen.WriteString(line)
+			screen.WriteString("\x1b[K\n")
+		}
+	}
+
+	if hidden := len(tiles) - shown; hidden > 0 {
+		screen.WriteString(fmt.Sprintf(" +%d more (enlarge the terminal or use --pin)", hidden))
+	} else if len(tiles) == 0 {
+		screen.WriteString(" No matches to show")
+	}
+	screen.WriteString("\x1b[K\x1b[J")
+
+	fmt.Print(screen.String())
+}
+
+// tile renders one match as tileHeight lines of exactly width columns
+func (g *gridView) tile(event Event, width int, now time.Time) []string {
+	inner := width - 4
+	m := normalizeMatch(event)
+
+	status := matchMinute(event)
+	if m.State == "pre" {
+		if t, err := parseEventTime(event.Date); err == nil {
+			status = "Kick-off " + t.Local().Format("15:04")
+		}
+	}
+
+	score := func(side apiSide) string {
+		s := scoreText(side.Score)
+		return padRight(side.Name, inner-len(s)-1) + " " + s
+	}
+
+	body := []string{
+		padRight(tuiLeagueName(event), inner),
+		score(m.Home),
+		score(m.Away),
+		padRight(status+"  "+lastMatchEvent(event), inner),
+	}
+
+	border := color.New(color.Faint)
+	text := color.New(color.Reset)
+	switch {
+	case now.Before(g.flashing[m.ID]) && now.UnixMilli()/500%2 == 0:
+		border = color.New(color.FgYellow, color.Bold)
+		text = color.New(color.FgBlack, color.BgYellow, color.Bold)
+	case now.Before(g.flashing[m.ID]):
+		border = color.New(color.FgYellow, color.Bold)
+	case m.State == "in":
+		border = color.New(color.FgRed)
+	}
+
+	lines := []string{border.Sprint("┌" + strings.Repeat("─", width-2) + "┐")}
+	for _, line := range body {
+		lines = append(lines, border.Sprint("│")+text.Sprint(" "+line+" ")+border.Sprint("│"))
+	}
+	return append(lines, border.Sprint("└"+strings.Repeat("─", width-2)+"┘"))
+}
+
+// matchMinute is the match clock from the status detail, falling back to
+// the clock of the latest play
+func matchMinute(event Event) string {
+	if detail := event.Status.Type.ShortDetail; detail != "" {
+		return detail
+	}
+	if detail := event.Status.Type.Detail; detail != "" {
+		return detail
+	}
+	if len(event.Competitions) > 0 {
+		if details := event.Competitions[0].Details; len(details) > 0 {
+			return details[len(details)-1].Clock.DisplayValue
+		}
+	}
+	return ""
+}
+
+// lastMatchEvent describes the latest goal or card of a match
+func lastMatchEvent(event Event) string {
+	if len(event.Competitions) == 0 || len(event.Competitions[0].Details) == 0 {
+		return ""
+	}
+	d := event.Competitions[0].Details[len(event.Competitions[0].Details)-1]
+
+	text := d.Type.Name
+	if len(d.AthletesInvolved) > 0 {
+		text += " - " + d.AthletesInvolved[0].DisplayName
+	}
+	return strings.TrimSpace(d.Clock.DisplayValue + " " + text)
+}
@@ -0,0 +1,314 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// --har records every HTTP request the command makes, retries included, and
+// writes them as a HAR 1.2 file when it exits, so a bug report can carry
+// exactly what the API answered. Browser devtools can open the file.
+//
+// Credentials in headers and URLs are redacted, and notifier requests keep
+// only their host, since a webhook URL is itself a secret. Response bodies
+// are kept as they are.
+
+// harMaxEntries caps how many requests are kept, so a long-running command
+// such as serve or notify doesn't grow without bound; the oldest are dropped
+const harMaxEntries = 1000
+
+// harSecretParam matches query parameters whose values are redacted
+var harSecretParam = regexp.MustCompile(`(?i)token|key|secret|pass|auth|sig|credential`)
+
+// harFile is the --har flag
+var harFile string
+
+// harLog collects the entries while --har is set; nil otherwise
+var harLog *harRecorder
+
+type harRecorder struct {
+	mu      sync.Mutex
+	entries []harEntry
+	dropped int
+}
+
+type harEntry struct {
+	started time.Time
+
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+	// Error is set for requests that got no response
+	Error string `json:"_error,omitempty"`
+}
+
+type harRequest struct {
+	Method      string       `json:"method"`
+	URL         string       `json:"url"`
+	HTTPVersion string       `json:"httpVersion"`
+	Cookies     []harNameVal `json:"cookies"`
+	Headers     []harNameVal `json:"headers"`
+	QueryString []harNameVal `json:"queryString"`
+	PostData    *harPostData `json:"postData,omitempty"`
+	HeadersSize int          `json:"headersSize"`
+	BodySize    int          `json:"bodySize"`
+}
+
+type harResponse struct {
+	Status      int          `json:"status"`
+	StatusText  string       `json:"statusText"`
+	HTTPVersion string       `json:"httpVersion"`
+	Cookies     []harNameVal `json:"cookies"`
+	Headers     []harNameVal `json:"headers"`
+	Content     harContent   `json:"content"`
+	RedirectURL string       `json:"redirectURL"`
+	HeadersSize int          `json:"headersSize"`
+	BodySize    int          `json:"bodySize"`
+}
+
+type harNameVal struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type harContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+}
+
+// harTimings are in milliseconds; send isn't measured separately
+type harTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// harTransport records the requests sent through next while --har is set
+func harTransport(next http.RoundTripper) http.RoundTripper {
+	if harLog == nil {
+		return next
+	}
+	return &harRoundTripper{next: next, log: harLog}
+}
+
+// harWebhookTransport is harTransport for notifier requests, whose URL
+// paths are redacted
+func harWebhookTransport(next http.RoundTripper) http.RoundTripper {
+	if harLog == nil {
+		return next
+	}
+	return &harRoundTripper{next: next, log: harLog, redactPath: true}
+}
+
+type harRoundTripper struct {
+	next       http.RoundTripper
+	log        *harRecorder
+	redactPath bool
+}
+
+func (t *harRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil && req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			reqBody, _ = io.ReadAll(body)
+			body.Close()
+		}
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	wait := time.Since(start)
+
+	entry := harEntry{
+		started:         start,
+		StartedDateTime: start.Format(time.RFC3339Nano),
+		Request:         harRequestFor(req, reqBody, t.redactPath),
+		Response: harResponse{
+			Cookies:     []harNameVal{},
+			Headers:     []harNameVal{},
+			Content:     harContent{MimeType: "x-unknown"},
+			HeadersSize: -1,
+			BodySize:    -1,
+		},
+		Timings: harTimings{Wait: harMillis(wait)},
+	}
+	if err != nil {
+		entry.Error = err.Error()
+		entry.Time = harMillis(wait)
+		t.log.add(entry)
+		return nil, err
+	}
+
+	// Read the body here so its content and transfer time are recorded;
+	// the caller gets an in-memory copy
+	body, readErr := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	receive := time.Since(start) - wait
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	entry.Response.Status = resp.StatusCode
+	entry.Response.StatusText = strings.TrimSpace(strings.TrimPrefix(resp.Status, fmt.Sprint(resp.StatusCode)))
+	entry.Response.HTTPVersion = resp.Proto
+	entry.Response.Headers = harHeaders(resp.Header)
+	entry.Response.Content = harContent{
+		Size:     len(body),
+		MimeType: defaultIfEmpty(resp.Header.Get("Content-Type"), "x-unknown"),
+		Text:     string(body),
+	}
+	entry.Response.BodySize = len(body)
+	if loc := resp.Header.Get("Location"); loc != "" {
+		entry.Response.RedirectURL = loc
+	}
+	entry.Timings.Receive = harMillis(receive)
+	entry.Time = harMillis(wait + receive)
+	if readErr != nil {
+		entry.Error = readErr.Error()
+	}
+	t.log.add(entry)
+
+	if readErr != nil {
+		return nil, readErr
+	}
+	return resp, nil
+}
+
+func harRequestFor(req *http.Request, body []byte, redactPath bool) harRequest {
+	u := harRedactURL(req.URL, redactPath)
+	r := harRequest{
+		Method:      req.Method,
+		URL:         u.String(),
+		HTTPVersion: defaultIfEmpty(req.Proto, "HTTP/1.1"),
+		Cookies:     []harNameVal{},
+		Headers:     harHeaders(req.Header),
+		QueryString: []harNameVal{},
+		HeadersSize: -1,
+		BodySize:    len(body),
+	}
+	for name, values := range req.URL.Query() {
+		for _, value := range values {
+			if harSecretParam.MatchString(name) {
+				value = "[redacted]"
+			}
+			r.QueryString = append(r.QueryString, harNameVal{name, value})
+		}
+	}
+	sort.Slice(r.QueryString, func(i, j int) bool { return r.QueryString[i].Name < r.QueryString[j].Name })
+	if body != nil {
+		r.PostData = &harPostData{MimeType: req.Header.Get("Content-Type"), Text: string(body)}
+	}
+	return r
+}
+
+// harRedactURL returns u without userinfo and with secret-looking query
+// values redacted; redactPath also hides the path
+func harRedactURL(u *url.URL, redactPath bool) *url.URL {
+	redacted := *u
+	redacted.User = nil
+	if redactPath {
+		redacted.Path, redacted.RawPath = "/[redacted]", "/[redacted]"
+	}
+	if redacted.RawQuery != "" {
+		params := strings.Split(redacted.RawQuery, "&")
+		for i, param := range params {
+			name, _, _ := strings.Cut(param, "=")
+			if unescaped, err := url.QueryUnescape(name); err == nil && harSecretParam.MatchString(unescaped) {
+				params[i] = name + "=[redacted]"
+			}
+		}
+		redacted.RawQuery = strings.Join(params, "&")
+	}
+	return &redacted
+}
+
+// harHeaders lists headers in a stable order, with credentials redacted
+func harHeaders(h http.Header) []harNameVal {
+	headers := []harNameVal{}
+	for name, values := range h {
+		for _, value := range values {
+			switch http.CanonicalHeaderKey(name) {
+			case "Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie":
+				value = "[redacted]"
+			}
+			headers = append(headers, harNameVal{name, value})
+		}
+	}
+	sort.SliceStable(headers, func(i, j int) bool { return headers[i].Name < headers[j].Name })
+	return headers
+}
+
+func harMillis(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+func (r *harRecorder) add(e harEntry) {
+	r.mu.Lock()
+	if len(r.entries) == harMaxEntries {
+		r.entries = append(r.entries[:0], r.entries[1:]...)
+		r.dropped++
+	}
+	r.entries = append(r.entries, e)
+	r.mu.Unlock()
+}
+
+// writeHAR saves the recorded requests to the --har file, if one was given
+func writeHAR() error {
+	if harLog == nil {
+		return nil
+	}
+	harLog.mu.Lock()
+	entries := append([]harEntry{}, harLog.entries...)
+	dropped := harLog.dropped
+	harLog.mu.Unlock()
+	if dropped > 0 {
+		slog.Warn("HAR file holds only the most recent requests", "kept", len(entries), "dropped", dropped)
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].started.Before(entries[j].started) })
+
+	var doc struct {
+		Log struct {
+			Version string `json:"version"`
+			Creator struct {
+				Name    string `json:"name"`
+				Version string `json:"version"`
+			} `json:"creator"`
+			Entries []harEntry `json:"entries"`
+		} `json:"log"`
+	}
+	doc.Log.Version = "1.2"
+	doc.Log.Creator.Name = "sharingan"
+	doc.Log.Creator.Version = "dev"
+	doc.Log.Entries = entries
+
+	// URLs read better without & escaped as \u0026
+	var out bytes.Buffer
+	enc := json.NewEncoder(&out)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	if err := os.WriteFile(harFile, out.Bytes(), 0644); err != nil {
+		return fmt.Errorf("writing HAR file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Settings for fetching several leagues at once, set by the --concurrency
+// and --league-timeout flags
+var (
+	leagueConcurrency int
+	leagueTimeout     time.Duration
+)
+
+// leagueSlugPattern is the shape of a league slug: eng.1, uefa.champions,
+// esp.copa_del_rey
+var leagueSlugPattern = regexp.MustCompile(`^[a-z]+\.[a-z0-9_]+$`)
+
+// isLeagueSlug reports whether a --league value names a single league by slug
+func isLeagueSlug(value string) bool {
+	return leagueSlugPattern.MatchString(strings.ToLower(strings.TrimSpace(value)))
+}
+
+// leagueSlugs splits a --league value into league slugs such as eng.1. It
+// returns nil unless every entry is a slug, in which case the value is a
+// name filter for the all-leagues feed instead.
+func leagueSlugs(value string) []string {
+	if value == "" {
+		return nil
+	}
+	var slugs []string
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(value, ",") {
+		slug := strings.ToLower(strings.TrimSpace(part))
+		if !leagueSlugPattern.MatchString(slug) {
+			return nil
+		}
+		if !seen[slug] {
+			seen[slug] = true
+			slugs = append(slugs, slug)
+		}
+	}
+	return slugs
+}
+
+// leagueFailure is a league whose scoreboard couldn't be fetched
+type leagueFailure struct {
+	League string
+	Err    error
+}
+
+// fetchLeagueScoreboards fetches each league's own scoreboard for a date
+// (YYYYMMDD, empty for today), at most leagueConcurrency at a time and each
+// within leagueTimeout. Events come back in the order the leagues were
+// given. Leagues that fail are returned separately, so callers can show
+// partial results; the error is only set when every league failed.
+func fetchLeagueScoreboards(ctx context.Context, slugs []string, day string) ([]Event, []leagueFailure, error) {
+	results := make([][]Event, len(slugs))
+	errs := make([]error, len(slugs))
+
+	workers := leagueConcurrency
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(slugs) {
+		workers = len(slugs)
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i], errs[i] = fetchLeagueScoreboard(ctx, slugs[i], day)
+			}
+		}()
+	}
+	for i := range slugs {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	var events []Event
+	var failures []leagueFailure
+	seen := make(map[string]bool)
+	for i, slug := range slugs {
+		if errs[i] != nil {
+			failures = append(failures, leagueFailure{League: slug, Err: errs[i]})
+			continue
+		}
+		for _, event := range results[i] {
+			if !seen[event.ID] {
+				seen[event.ID] = true
+				events = append(events, event)
+			}
+		}
+	}
+	if len(failures) == len(slugs) {
+		return nil, failures, failures[0].Err
+	}
+	return events, failures, nil
+}
+
+// fetchLeagueScoreboard fetches one league's scoreboard within leagueTimeout
+func fetchLeagueScoreboard(ctx context.Context, slug, day string) ([]Event, error) {
+	if leagueTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, leagueTimeout)
+		defer cancel()
+	}
+
+	url := fmt.Sprintf("%s/%s/scoreboard", espnSoccerBase, slug)
+	if day != "" {
+		url += "?dates=" + day
+	}
+	body, err := fetchESPN(ctx, url)
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, &networkError{URL: url, Err: fmt.Errorf("timed out after %s", leagueTimeout)}
+		}
+		return nil, err
+	}
+
+	var data ESPNResponse
+	if err := decodeESPN(slug+" scoreboard", body, &data); err != nil {
+		return nil, err
+	}
+	if len(data.Leagues) == 0 && len(data.Events) == 0 {
+		return nil, errNotFound("league %s not found", slug)
+	}
+
+	// League feeds name the league once, not on every event
+	for i := range data.Events {
+		if data.Events[i].League.Slug == "" && len(data.Leagues) > 0 {
+			data.Events[i].League = data.Leagues[0]
+			data.Events[i].League.Slug = defaultIfEmpty(data.Leagues[0].Slug, slug)
+		}
+	}
+	return data.Events, nil
+}
@@ -1,12 +1,10 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
-	"log"
-	"net/http"
-	"os"
+	"log/slog"
 	"strings"
 	"time"
 
@@ -28,16 +26,31 @@ Examples:
   # Get live scores for a specific league
   sharingan live --league EPL
 
+  # Fetch several leagues' own scoreboards in parallel
+  sharingan live --league eng.1,esp.1,ger.1,ita.1,fra.1 --concurrency 3
+
   # Get detailed match information
   sharingan live --detailed
+
+  # Follow several matches at once as tiles, pinning two of them first
+  sharingan live --grid --pin 704512,704519
 `,
-	Run: func(cmd *cobra.Command, args []string) {
-		fetchLiveMatches()
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if gridMode {
+			runGrid(cmd.Context())
+			return nil
+		}
+		return fetchLiveMatches(cmd.Context())
 	},
 }
 
+var (
+	gridMode      bool
+	pinnedMatches []string
+)
+
 // displayMatches displays a list of matches based on their state
-func displayMatches(matches []Event, state string) {
+func displayMatches(ctx context.Context, matches []Event, state string) {
 	for _, match := range matches {
 		status := strings.ToUpper(match.Status.Type.Detail)
 		homeTeam := match.Competitions[0].Competitors[0]
@@ -52,6 +65,15 @@ func displayMatches(matches []Event, state string) {
 			fmt.Printf("Venue: %s\n", match.Competitions[0].Venue.FullName)
 			fmt.Printf("Start Time: %s\n", match.Date)
 			fmt.Printf("League: %s\n", match.League.Name)
+
+			// Estimate the outcome of fixtures that haven't started
+			if state == "upcoming" {
+				if home, away, ok := homeAndAway(match.Competitions[0]); ok {
+					if prediction, ok := predictionFor(ctx, eventLeagueSlug(match), home.Team, away.Team); ok {
+						displayPrediction(prediction)
+					}
+				}
+			}
 			fmt.Println()
 		}
 	}
@@ -61,64 +83,108 @@ func init() {
 	rootCmd.AddCommand(liveCmd)
 
 	// Add flags
-	liveCmd.Flags().StringVarP(&league, "league", "l", "", "Filter by league (e.g. EPL, La Liga)")
+	liveCmd.Flags().StringVarP(&league, "league", "l", "", "Filter by league (e.g. EPL, La Liga), or a comma-separated list of league slugs (e.g. eng.1,esp.1)")
 	liveCmd.Flags().BoolVarP(&detailed, "detailed", "d", false, "Show detailed match information")
 	liveCmd.Flags().StringVarP(&format, "format", "f", "pretty", "Output format (pretty, json)")
+	liveCmd.Flags().BoolVar(&gridMode, "grid", false, "Show matches as tiles that refresh until interrupted")
+	liveCmd.Flags().StringSliceVar(&pinnedMatches, "pin", nil, "Match IDs to always show first in the grid")
+	liveCmd.Flags().DurationVar(&refreshInterval, "interval", 30*time.Second, "How often the grid refreshes")
+	liveCmd.Flags().IntVar(&leagueConcurrency, "concurrency", 4, "How many league scoreboards to fetch at once")
+	liveCmd.Flags().DurationVar(&leagueTimeout, "league-timeout", 10*time.Second, "Give up on a league's scoreboard after this long")
 }
 
-func fetchLiveMatches() {
-	url := "https://site.api.espn.com/apis/site/v2/sports/soccer/all/scoreboard"
+func fetchLiveMatches(ctx context.Context) error {
+	var filteredEvents []Event
+	if slugs := leagueSlugs(league); slugs != nil {
+		slog.Info("fetching live matches", "leagues", strings.Join(slugs, ","))
 
-	client := &http.Client{
-		Timeout: 30 * time.Second,
+		events, failures, err := fetchLeagueScoreboards(ctx, slugs, "")
+		if err != nil {
+			return err
+		}
+		for _, f := range failures {
+			slog.Warn("skipping league", "league", f.League, "err", f.Err)
+		}
+		printFallbackBanner()
+
+		if format == "json" {
+			out, _ := json.MarshalIndent(ESPNResponse{Events: events}, "", "  ")
+			fmt.Println(string(out))
+			return nil
+		}
+		filteredEvents = events
+	} else {
+		events, done, err := fetchAllLiveMatches(ctx)
+		if err != nil || done {
+			return err
+		}
+		filteredEvents = events
 	}
 
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		log.Fatalf("Error creating request: %v", err)
+	// Display matches
+	if len(filteredEvents) == 0 {
+		fmt.Println("No matches found for today.")
+		return nil
 	}
 
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/114.0.0.0 Safari/537.36")
-	req.Header.Set("Accept", "application/json")
-	req.Header.Set("Accept-Language", "en-US,en;q=0.9")
+	// Group matches by state (live, upcoming, completed)
+	liveMatches, upcomingMatches, completedMatches := groupMatchesByState(filteredEvents)
+
+	// Display live matches first
+	if len(liveMatches) > 0 {
+		liveHeader := color.New(color.FgRed, color.Bold).SprintFunc()
+		fmt.Println("\n" + liveHeader("🔴 LIVE MATCHES"))
+		fmt.Println("=================================")
+		displayMatches(ctx, liveMatches, "live")
+	}
 
-	fmt.Println("Fetching live football matches from ESPN API...")
+	// Display upcoming matches
+	if len(upcomingMatches) > 0 {
+		upcomingHeader := color.New(color.FgYellow, color.Bold).SprintFunc()
+		fmt.Println("\n" + upcomingHeader("⏳ UPCOMING MATCHES"))
+		fmt.Println("=================================")
+		displayMatches(ctx, upcomingMatches, "upcoming")
+	}
 
-	resp, err := client.Do(req)
-	if err != nil {
-		log.Fatalf("Error fetching data: %v", err)
+	// Display completed matches
+	if len(completedMatches) > 0 {
+		completedHeader := color.New(color.FgGreen, color.Bold).SprintFunc()
+		fmt.Println("\n" + completedHeader("✅ COMPLETED MATCHES"))
+		fmt.Println("=================================")
+		displayMatches(ctx, completedMatches, "completed")
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	// Display total count
+	fmt.Printf("\nTotal matches: %d (Live: %d, Upcoming: %d, Completed: %d)\n",
+		len(filteredEvents), len(liveMatches), len(upcomingMatches), len(completedMatches))
+	return nil
+}
+
+// fetchAllLiveMatches fetches today's matches from the all-leagues feed and
+// applies --league as a name filter. done is set when the raw JSON has
+// already been printed.
+func fetchAllLiveMatches(ctx context.Context) (filteredEvents []Event, done bool, err error) {
+	url := "https://site.api.espn.com/apis/site/v2/sports/soccer/all/scoreboard"
+
+	slog.Info("fetching live matches", "url", url)
+
+	body, err := fetchESPN(ctx, url)
 	if err != nil {
-		log.Fatalf("Error reading response: %v", err)
+		return nil, false, err
 	}
+	printFallbackBanner()
 
 	if format == "json" {
 		fmt.Println(string(body))
-		return
-	}
-
-	// Save response for debugging if DEBUG env var is set
-	if os.Getenv("DEBUG") == "true" {
-		if err := os.WriteFile("espn_response.json", body, 0644); err != nil {
-			log.Printf("Warning: Failed to save response to file: %v", err)
-		} else {
-			fmt.Println("Saved raw response to espn_response.json")
-		}
+		return nil, true, nil
 	}
 
 	var espnData ESPNResponse
-	err = json.Unmarshal(body, &espnData)
-	if err != nil {
-		log.Printf("Error parsing JSON: %v", err)
-		fmt.Printf("Response sample: %s\n", string(body[:min(1000, len(body))]))
-		return
+	if err := decodeESPN("scoreboard", body, &espnData); err != nil {
+		return nil, false, err
 	}
 
 	// Apply league filter if specified
-	var filteredEvents []Event
 	if league != "" {
 		for _, event := range espnData.Events {
 			// Check if event matches the league filter
@@ -137,52 +203,34 @@ func fetchLiveMatches() {
 	} else {
 		filteredEvents = espnData.Events
 	}
+	return filteredEvents, false, nil
+}
 
-	// Display matches
-	if len(filteredEvents) == 0 {
-		fmt.Println("No matches found for today.")
-		return
-	}
-
-	// Group matches by state (live, upcoming, completed)
-	var liveMatches, upcomingMatches, completedMatches []Event
-
-	for _, event := range filteredEvents {
+// groupMatchesByState splits events into live, upcoming and completed matches
+func groupMatchesByState(events []Event) (live, upcoming, completed []Event) {
+	for _, event := range events {
 		switch event.Status.Type.State {
 		case "in":
-			liveMatches = append(liveMatches, event)
+			live = append(live, event)
 		case "pre":
-			upcomingMatches = append(upcomingMatches, event)
+			upcoming = append(upcoming, event)
 		case "post":
-			completedMatches = append(completedMatches, event)
+			completed = append(completed, event)
 		}
 	}
+	return live, upcoming, completed
+}
 
-	// Display live matches first
-	if len(liveMatches) > 0 {
-		liveHeader := color.New(color.FgRed, color.Bold).SprintFunc()
-		fmt.Println("\n" + liveHeader("🔴 LIVE MATCHES"))
-		fmt.Println("=================================")
-		displayMatches(liveMatches, "live")
-	}
-
-	// Display upcoming matches
-	if len(upcomingMatches) > 0 {
-		upcomingHeader := color.New(color.FgYellow, color.Bold).SprintFunc()
-		fmt.Println("\n" + upcomingHeader("⏳ UPCOMING MATCHES"))
-		fmt.Println("=================================")
-		displayMatches(upcomingMatches, "upcoming")
+// fetchTodayEvents retrieves today's matches across all leagues
+func fetchTodayEvents(ctx context.Context) ([]Event, error) {
+	body, err := fetchESPN(ctx, espnSoccerBase+"/all/scoreboard")
+	if err != nil {
+		return nil, err
 	}
 
-	// Display completed matches
-	if len(completedMatches) > 0 {
-		completedHeader := color.New(color.FgGreen, color.Bold).SprintFunc()
-		fmt.Println("\n" + completedHeader("✅ COMPLETED MATCHES"))
-		fmt.Println("=================================")
-		displayMatches(completedMatches, "completed")
+	var espnData ESPNResponse
+	if err := decodeESPN("scoreboard", body, &espnData); err != nil {
+		return nil, err
 	}
-
-	// Display total count
-	fmt.Printf("\nTotal matches: %d (Live: %d, Upcoming: %d, Completed: %d)\n",
-		len(filteredEvents), len(liveMatches), len(upcomingMatches), len(completedMatches))
+	return espnData.Events, nil
 }
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"io"
+	"log/slog"
+	"os"
+)
+
+// Logging settings, set by -v/--verbose and --log-format
+var (
+	verbosity int
+	logFormat string
+)
+
+// setupLogging sends diagnostics to stderr through log/slog, so stdout only
+// ever carries the requested output. Warnings and errors are shown by
+// default, -v adds progress and -vv adds every upstream request; --quiet
+// silences logging altogether.
+func setupLogging() error {
+	level := slog.LevelWarn
+	switch {
+	case verbosity == 1:
+		level = slog.LevelInfo
+	case verbosity >= 2:
+		level = slog.LevelDebug
+	}
+
+	var w io.Writer = os.Stderr
+	if quiet {
+		w = io.Discard
+	}
+
+	options := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	switch logFormat {
+	case "text", "":
+		handler = slog.NewTextHandler(w, options)
+	case "json":
+		handler = slog.NewJSONHandler(w, options)
+	default:
+		return errInvalidInput("unknown log format %q (use text or json)", logFormat)
+	}
+	// Also routes the standard log package, which some libraries use
+	slog.SetDefault(slog.New(handler))
+	return nil
+}
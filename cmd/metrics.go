@@ -0,0 +1,279 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Metrics are kept in a small hand-rolled registry and exposed in the
+// Prometheus text format (version 0.0.4)
+
+// metric is anything that can write itself in the exposition format
+type metric interface {
+	write(w io.Writer)
+}
+
+// counterVec is a counter partitioned by label values
+type counterVec struct {
+	name, help string
+	labels     []string
+
+	mu     sync.Mutex
+	values map[string]float64
+}
+
+func newCounterVec(name, help string, labels ...string) *counterVec {
+	return &counterVec{name: name, help: help, labels: labels, values: make(map[string]float64)}
+}
+
+func (c *counterVec) inc(labelValues ...string) {
+	c.mu.Lock()
+	c.values[strings.Join(labelValues, "\x00")]++
+	c.mu.Unlock()
+}
+
+func (c *counterVec) write(w io.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", c.name, c.help, c.name)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range sortedKeys(c.values) {
+		fmt.Fprintf(w, "%s%s %s\n", c.name, formatLabels(c.labels, key, "", ""), formatFloat(c.values[key]))
+	}
+}
+
+// histogramVec is a histogram partitioned by label values
+type histogramVec struct {
+	name, help string
+	labels     []string
+	buckets    []float64
+
+	mu     sync.Mutex
+	series map[string]*histogramSeries
+}
+
+type histogramSeries struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+func newHistogramVec(name, help string, buckets []float64, labels ...string) *histogramVec {
+	return &histogramVec{name: name, help: help, labels: labels, buckets: buckets, series: make(map[string]*histogramSeries)}
+}
+
+func (h *histogramVec) observe(value float64, labelValues ...string) {
+	key := strings.Join(labelValues, "\x00")
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogramSeries{counts: make([]uint64, len(h.buckets))}
+		h.series[key] = s
+	}
+	for i, upper := range h.buckets {
+		if value <= upper {
+			s.counts[i]++
+		}
+	}
+	s.count++
+	s.sum += value
+}
+
+func (h *histogramVec) write(w io.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", h.name, h.help, h.name)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	keys := make([]string, 0, len(h.series))
+	for key := range h.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		s := h.series[key]
+		for i, upper := range h.buckets {
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, formatLabels(h.labels, key, "le", formatFloat(upper)), s.counts[i])
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, formatLabels(h.labels, key, "le", "+Inf"), s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, formatLabels(h.labels, key, "", ""), formatFloat(s.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, formatLabels(h.labels, key, "", ""), s.count)
+	}
+}
+
+// gaugeFunc reports a value computed at scrape time
+type gaugeFunc struct {
+	name, help string
+	value      func() float64
+}
+
+func (g gaugeFunc) write(w io.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n%s %s\n", g.name, g.help, g.name, g.name, formatFloat(g.value()))
+}
+
+func sortedKeys(m map[string]float64) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// formatLabels renders {name="value",...}, optionally with one extra label
+func formatLabels(names []string, key, extraName, extraValue string) string {
+	var pairs []string
+	if len(names) > 0 {
+		for i, value := range strings.Split(key, "\x00") {
+			pairs = append(pairs, fmt.Sprintf("%s=%q", names[i], value))
+		}
+	}
+	if extraName != "" {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", extraName, extraValue))
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// Upstream and cache metrics, recorded by retryTransport and responseCache
+var (
+	upstreamRequests = newCounterVec("sharingan_upstream_requests_total",
+		"Requests made to the upstream provider.", "endpoint", "code")
+	upstreamLatency = newHistogramVec("sharingan_upstream_request_duration_seconds",
+		"Latency of upstream requests.", []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}, "endpoint")
+	upstreamRetries = newCounterVec("sharingan_upstream_retries_total",
+		"Upstream requests retried after a network error or retryable status.", "endpoint")
+	cacheRequests = newCounterVec("sharingan_cache_requests_total",
+		"Lookups in the upstream response cache by result (hit, miss, shared).", "result")
+
+	// lastUpstreamSuccess is the Unix time of the last 2xx upstream response
+	lastUpstreamSuccess atomic.Int64
+)
+
+// recordUpstream tracks one upstream request. code is the HTTP status, or 0
+// when no response was received.
+func recordUpstream(rawURL string, code int, elapsed time.Duration) {
+	endpoint := upstreamEndpoint(rawURL)
+	status := "error"
+	if code > 0 {
+		status = fmt.Sprint(code)
+	}
+	upstreamRequests.inc(endpoint, status)
+	upstreamLatency.observe(elapsed.Seconds(), endpoint)
+	if code >= 200 && code < 300 {
+		lastUpstreamSuccess.Store(time.Now().Unix())
+	}
+}
+
+// upstreamEndpoint reduces an upstream URL to a low-cardinality label
+func upstreamEndpoint(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "other"
+	}
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i := len(segments) - 1; i >= 0; i-- {
+		switch segments[i] {
+		case "scoreboard", "summary", "standings", "schedule":
+			return segments[i]
+		case "teams":
+			if i == len(segments)-1 {
+				return "teams"
+			}
+			return "team"
+		}
+	}
+	return "other"
+}
+
+// pollStats tracks the serve mode poll loop
+type pollStats struct {
+	interval time.Duration
+	lastPoll atomic.Int64 // Unix nanoseconds of the last completed poll
+}
+
+// lag is how far the poll loop is behind its schedule
+func (p *pollStats) lag() float64 {
+	last := p.lastPoll.Load()
+	if last == 0 {
+		return 0
+	}
+	late := time.Since(time.Unix(0, last)) - p.interval
+	if late < 0 {
+		return 0
+	}
+	return late.Seconds()
+}
+
+// handleMetrics writes every metric in the Prometheus text format
+func (s *apiServer) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	metrics := []metric{
+		upstreamRequests,
+		upstreamRetries,
+		upstreamLatency,
+		cacheRequests,
+		gaugeFunc{"sharingan_stream_subscribers", "Connected SSE and WebSocket subscribers.",
+			func() float64 { return float64(s.hub.count()) }},
+		gaugeFunc{"sharingan_poll_lag_seconds", "How far the scoreboard poll loop is behind schedule.",
+			s.poll.lag},
+		gaugeFunc{"sharingan_upstream_circuit_open", "1 while upstream requests are diverted to the fallback.",
+			func() float64 {
+				if espnBreaker.isOpen() {
+					return 1
+				}
+				return 0
+			}},
+		gaugeFunc{"sharingan_upstream_last_success_timestamp_seconds", "Unix time of the last successful upstream response.",
+			func() float64 { return float64(lastUpstreamSuccess.Load()) }},
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	for _, m := range metrics {
+		m.write(w)
+	}
+}
+
+// handleHealthz reports that the process is up
+func (s *apiServer) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// handleReadyz reports whether the provider has answered recently
+func (s *apiServer) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	last := lastUpstreamSuccess.Load()
+	if last == 0 {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"status": "provider not reached yet"})
+		return
+	}
+
+	since := time.Since(time.Unix(last, 0))
+	if since > readyWindow {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"status": fmt.Sprintf("provider unreachable for %s", since.Round(time.Second)),
+		})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
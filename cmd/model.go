@@ -1,5 +1,10 @@
 package cmd
 
+import (
+	"encoding/json"
+	"strconv"
+)
+
 // ESPN API response structures
 type ESPNResponse struct {
 	Events  []Event  `json:"events"`
@@ -18,7 +23,9 @@ type Event struct {
 }
 
 type Status struct {
-	Type StatusType `json:"type"`
+	Type         StatusType `json:"type"`
+	DisplayClock string     `json:"displayClock,omitempty"`
+	Period       int        `json:"period,omitempty"`
 }
 
 type StatusType struct {
@@ -28,6 +35,7 @@ type StatusType struct {
 	Completed   bool   `json:"completed"`
 	Description string `json:"description"`
 	Detail      string `json:"detail"`
+	ShortDetail string `json:"shortDetail,omitempty"`
 }
 
 type Competition struct {
@@ -53,7 +61,7 @@ type Venue struct {
 type Competitor struct {
 	ID       string `json:"id"`
 	Type     string `json:"type"`
-	Score    string `json:"score"`
+	Score    Score  `json:"score"`
 	HomeAway string `json:"homeAway"`
 	Team     Team   `json:"team"`
 	Winner   bool   `json:"winner,omitempty"`
@@ -61,6 +69,35 @@ type Competitor struct {
 	Stats    []Stat `json:"stats,omitempty"`
 }
 
+// Score is a competitor's score. Scoreboards send it as a string while the
+// schedule endpoints send an object with a value and display value.
+type Score string
+
+func (s *Score) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*s = Score(text)
+		return nil
+	}
+
+	var obj struct {
+		Value        *float64 `json:"value"`
+		DisplayValue string   `json:"displayValue"`
+	}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+	switch {
+	case obj.DisplayValue != "":
+		*s = Score(obj.DisplayValue)
+	case obj.Value != nil:
+		*s = Score(strconv.Itoa(int(*obj.Value)))
+	default:
+		*s = ""
+	}
+	return nil
+}
+
 type Team struct {
 	ID               string `json:"id"`
 	Location         string `json:"location"`
@@ -84,6 +121,17 @@ type DetailObj struct {
 		Value        float64 `json:"value"`
 		DisplayValue string  `json:"displayValue"`
 	} `json:"clock"`
+	Team struct {
+		ID string `json:"id"`
+	} `json:"team"`
+	ScoringPlay      bool `json:"scoringPlay"`
+	YellowCard       bool `json:"yellowCard"`
+	RedCard          bool `json:"redCard"`
+	PenaltyKick      bool `json:"penaltyKick"`
+	OwnGoal          bool `json:"ownGoal"`
+	AthletesInvolved []struct {
+		DisplayName string `json:"displayName"`
+	} `json:"athletesInvolved,omitempty"`
 }
 
 type Note struct {
@@ -103,6 +151,15 @@ type League struct {
 	ShortName    string `json:"shortName,omitempty"`
 	Slug         string `json:"slug,omitempty"`
 	LogoURL      string `json:"logos.dark.href,omitempty"`
+	Season       Season `json:"season,omitempty"`
+}
+
+// Season is the current season of a league as reported by the scoreboard
+type Season struct {
+	Year        int    `json:"year"`
+	StartDate   string `json:"startDate"`
+	EndDate     string `json:"endDate"`
+	DisplayName string `json:"displayName"`
 }
 
 type Stat struct {
@@ -110,6 +167,50 @@ type Stat struct {
 	Value string `json:"value"`
 }
 
+// matchSummary is the part of ESPN's match summary endpoint we use
+type matchSummary struct {
+	Header struct {
+		ID           string        `json:"id"`
+		Competitions []Competition `json:"competitions"`
+		League       League        `json:"league"`
+	} `json:"header"`
+	KeyEvents []KeyEvent `json:"keyEvents,omitempty"`
+	GameInfo  struct {
+		Venue      Venue `json:"venue"`
+		Attendance int   `json:"attendance"`
+	} `json:"gameInfo,omitempty"`
+}
+
+// KeyEvent is a goal, card or substitution in a match summary
+type KeyEvent struct {
+	Type struct {
+		Text string `json:"text"`
+	} `json:"type"`
+	Clock struct {
+		DisplayValue string `json:"displayValue"`
+	} `json:"clock"`
+	Text string `json:"text"`
+	Team struct {
+		DisplayName string `json:"displayName"`
+	} `json:"team"`
+	Participants []struct {
+		Athlete struct {
+			DisplayName string `json:"displayName"`
+		} `json:"athlete"`
+	} `json:"participants,omitempty"`
+}
+
+// TeamsResponse is the team list of a league, or of every league
+type TeamsResponse struct {
+	Sports []struct {
+		Leagues []struct {
+			Teams []struct {
+				Team Team `json:"team"`
+			} `json:"teams"`
+		} `json:"leagues"`
+	} `json:"sports"`
+}
+
 // TeamResponse for team API responses
 type TeamResponse struct {
 	Team       Team         `json:"team"`
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"time"
+)
+
+// A minimal MQTT 3.1.1 client: enough to connect and publish at QoS 0, with
+// or without the retain flag
+
+const (
+	mqttConnect    = 1 << 4
+	mqttConnack    = 2 << 4
+	mqttPublish    = 3 << 4
+	mqttDisconnect = 14 << 4
+)
+
+// mqttConn is a connection to an MQTT broker
+type mqttConn struct {
+	conn net.Conn
+	w    *bufio.Writer
+}
+
+// dialMQTT connects to a broker given as mqtt://[user:pass@]host[:port] or
+// mqtts:// for TLS
+func dialMQTT(rawURL, clientID string) (*mqttConn, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var conn net.Conn
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	switch u.Scheme {
+	case "mqtt", "tcp":
+		conn, err = dialer.Dial("tcp", hostWithPort(u, "1883"))
+	case "mqtts", "ssl", "tls":
+		conn, err = tls.DialWithDialer(dialer, "tcp", hostWithPort(u, "8883"), &tls.Config{ServerName: u.Hostname()})
+	default:
+		return nil, fmt.Errorf("unsupported MQTT scheme %q", u.Scheme)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c := &mqttConn{conn: conn, w: bufio.NewWriter(conn)}
+	if err := c.connect(u, clientID); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+func hostWithPort(u *url.URL, port string) string {
+	if u.Port() != "" {
+		return u.Host
+	}
+	return net.JoinHostPort(u.Hostname(), port)
+}
+
+// connect sends CONNECT and waits for the broker's CONNACK
+func (c *mqttConn) connect(u *url.URL, clientID string) error {
+	var flags byte = 0x02 // clean session
+	var payload []byte
+	payload = appendMQTTString(payload, clientID)
+	if user := u.User; user != nil {
+		flags |= 0x80
+		payload = appendMQTTString(payload, user.Username())
+		if password, ok := user.Password(); ok {
+			flags |= 0x40
+			payload = appendMQTTString(payload, password)
+		}
+	}
+
+	var header []byte
+	header = appendMQTTString(header, "MQTT")
+	header = append(header, 4, flags) // protocol level 3.1.1
+	header = binary.BigEndian.AppendUint16(header, 0)
+
+	if err := c.writePacket(mqttConnect, append(header, payload...)); err != nil {
+		return err
+	}
+
+	c.conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+	defer c.conn.SetReadDeadline(time.Time{})
+
+	ack := make([]byte, 4)
+	if _, err := io.ReadFull(c.conn, ack); err != nil {
+		return fmt.Errorf("reading CONNACK: %w", err)
+	}
+	if ack[0] != mqttConnack {
+		return fmt.Errorf("expected CONNACK, got packet type %d", ack[0]>>4)
+	}
+	if ack[3] != 0 {
+		return fmt.Errorf("broker refused connection (code %d)", ack[3])
+	}
+	return nil
+}
+
+// publish sends a QoS 0 message
+func (c *mqttConn) publish(topic string, payload []byte, retain bool) error {
+	var packetType byte = mqttPublish
+	if retain {
+		packetType |= 0x01
+	}
+	body := appendMQTTString(nil, topic)
+	return c.writePacket(packetType, append(body, payload...))
+}
+
+func (c *mqttConn) close() error {
+	c.writePacket(mqttDisconnect, nil)
+	return c.conn.Close()
+}
+
+// writePacket writes a fixed header with the remaining length as a
+// variable-length integer, followed by the body
+func (c *mqttConn) writePacket(packetType byte, body []byte) error {
+	c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	c.w.WriteByte(packetType)
+
+	n := len(body)
+	for {
+		b := byte(n % 128)
+		n /= 128
+		if n > 0 {
+			b |= 0x80
+		}
+		c.w.WriteByte(b)
+		if n == 0 {
+			break
+		}
+	}
+
+	c.w.Write(body)
+	return c.w.Flush()
+}
+
+func appendMQTTString(b []byte, s string) []byte {
+	b = binary.BigEndian.AppendUint16(b, uint16(len(s)))
+	return append(b, s...)
+}
@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A minimal NATS client: connect, publish, and answer the server's PINGs so
+// it keeps the connection open
+
+// natsConn is a connection to a NATS server
+type natsConn struct {
+	conn net.Conn
+
+	mu  sync.Mutex
+	w   *bufio.Writer
+	err error // set once the connection has failed
+}
+
+// dialNATS connects to a server given as nats://[user:pass@]host[:port]
+func dialNATS(rawURL string) (*natsConn, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "nats" && u.Scheme != "tcp" {
+		return nil, fmt.Errorf("unsupported NATS scheme %q", u.Scheme)
+	}
+
+	conn, err := net.DialTimeout("tcp", hostWithPort(u, "4222"), 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	c := &natsConn{conn: conn, w: bufio.NewWriter(conn)}
+	r := bufio.NewReader(conn)
+
+	conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+	info, err := r.ReadString('\n')
+	if err != nil || !strings.HasPrefix(info, "INFO ") {
+		conn.Close()
+		return nil, fmt.Errorf("expected INFO from server: %q %v", strings.TrimSpace(info), err)
+	}
+
+	options := map[string]interface{}{
+		"verbose":  false,
+		"pedantic": false,
+		"name":     "sharingan",
+		"lang":     "go",
+		"version":  "1",
+	}
+	if user := u.User; user != nil {
+		options["user"] = user.Username()
+		if password, ok := user.Password(); ok {
+			options["pass"] = password
+		}
+	}
+	connect, _ := json.Marshal(options)
+	fmt.Fprintf(c.w, "CONNECT %s\r\nPING\r\n", connect)
+	if err := c.w.Flush(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	// The server answers the PING with PONG once CONNECT is accepted, or
+	// with -ERR
+	reply, err := r.ReadString('\n')
+	if err != nil || strings.TrimSpace(reply) != "PONG" {
+		conn.Close()
+		return nil, fmt.Errorf("connecting: %q %v", strings.TrimSpace(reply), err)
+	}
+	conn.SetReadDeadline(time.Time{})
+
+	go c.readLoop(r)
+	return c, nil
+}
+
+// readLoop answers server PINGs and notices when the connection drops
+func (c *natsConn) readLoop(r *bufio.Reader) {
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			c.fail(err)
+			return
+		}
+		switch line = strings.TrimSpace(line); {
+		case line == "PING":
+			c.mu.Lock()
+			c.w.WriteString("PONG\r\n")
+			c.w.Flush()
+			c.mu.Unlock()
+		case strings.HasPrefix(line, "-ERR"):
+			if !natsFatalError(line) {
+				slog.Warn("NATS server rejected a message", "err", strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+				continue
+			}
+			c.fail(fmt.Errorf("server error: %s", line))
+			return
+		}
+	}
+}
+
+// natsFatalError reports whether the server closes the connection after an
+// -ERR. Only invalid subjects and permission violations leave it open.
+func natsFatalError(line string) bool {
+	message := strings.ToLower(line)
+	return !strings.Contains(message, "invalid subject") && !strings.Contains(message, "permissions violation")
+}
+
+func (c *natsConn) fail(err error) {
+	c.mu.Lock()
+	if c.err == nil {
+		c.err = err
+	}
+	c.mu.Unlock()
+	c.conn.Close()
+}
+
+// publish sends a message. NATS has no retained messages, so retain is
+// ignored; subscribers that need the latest state should use JetStream.
+func (c *natsConn) publish(subject string, payload []byte, retain bool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return c.err
+	}
+
+	c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	fmt.Fprintf(c.w, "PUB %s %d\r\n", subject, len(payload))
+	c.w.Write(payload)
+	c.w.WriteString("\r\n")
+	if err := c.w.Flush(); err != nil {
+		c.err = err
+		return err
+	}
+	return nil
+}
+
+func (c *natsConn) close() error {
+	c.fail(fmt.Errorf("closed"))
+	return nil
+}
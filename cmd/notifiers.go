@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"time"
+)
+
+// notifier delivers a match event somewhere outside the terminal
+type notifier interface {
+	Name() string
+	Notify(ctx context.Context, e matchEvent) error
+}
+
+// execTimeout bounds how long an exec notifier's command may run
+const execTimeout = 30 * time.Second
+
+// webhookNotifier POSTs the event as JSON, in the same shape the serve
+// mode streams use
+type webhookNotifier struct {
+	url string
+}
+
+func (n webhookNotifier) Name() string { return "webhook" }
+
+func (n webhookNotifier) Notify(ctx context.Context, e matchEvent) error {
+	return postJSON(ctx, n.url, e)
+}
+
+// slackNotifier posts to a Slack incoming webhook
+type slackNotifier struct {
+	url string
+}
+
+func (n slackNotifier) Name() string { return "slack" }
+
+func (n slackNotifier) Notify(ctx context.Context, e matchEvent) error {
+	return postJSON(ctx, n.url, map[string]string{"text": e.String()})
+}
+
+// discordNotifier posts to a Discord webhook
+type discordNotifier struct {
+	url string
+}
+
+func (n discordNotifier) Name() string { return "discord" }
+
+func (n discordNotifier) Notify(ctx context.Context, e matchEvent) error {
+	return postJSON(ctx, n.url, map[string]string{"content": e.String()})
+}
+
+// commandNotifier runs a shell command for each event. The event is passed
+// as JSON on stdin and summarised in SHARINGAN_EVENT_* variables.
+type commandNotifier struct {
+	command string
+}
+
+func (n commandNotifier) Name() string { return "exec" }
+
+func (n commandNotifier) Notify(ctx context.Context, e matchEvent) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, execTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(runCtx, "cmd", "/C", n.command)
+	} else {
+		cmd = exec.CommandContext(runCtx, "sh", "-c", n.command)
+	}
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(),
+		"SHARINGAN_EVENT_ID="+e.ID,
+		"SHARINGAN_EVENT_TYPE="+e.Type,
+		"SHARINGAN_EVENT_MESSAGE="+e.String(),
+		"SHARINGAN_MATCH_ID="+e.Match.ID,
+	)
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == nil && runCtx.Err() != nil {
+			return fmt.Errorf("running %q: timed out after %s", n.command, execTimeout)
+		}
+		return fmt.Errorf("running %q: %w", n.command, err)
+	}
+	return nil
+}
+
+// stdoutNotifier prints events, and is used when no other notifier is set
+type stdoutNotifier struct{}
+
+func (stdoutNotifier) Name() string { return "stdout" }
+
+func (stdoutNotifier) Notify(ctx context.Context, e matchEvent) error {
+	fmt.Printf("[%s] %s\n", e.Time.Local().Format("15:04"), e)
+	return nil
+}
+
+// postJSON sends v as a JSON request body and fails on a non-2xx response.
+// Errors name only the host: the rest of a webhook URL is a secret.
+func postJSON(ctx context.Context, rawURL string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	host := webhookHost(rawURL)
+	req, err := http.NewRequestWithContext(ctx, "POST", rawURL, bytes.NewReader(data))
+	if err != nil {
+		return errInvalidInput("invalid webhook URL for %s", host)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{Timeout: 15 * time.Second, Transport: harWebhookTransport(http.DefaultTransport)}
+	resp, err := client.Do(req)
+	if err != nil {
+		// *url.Error repeats the full URL
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("posting to %s: %w", host, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("posting to %s: %s", host, resp.Status)
+	}
+	return nil
+}
+
+// webhookHost is the host of a webhook URL, for messages that mustn't
+// reveal the URL itself
+func webhookHost(rawURL string) string {
+	if u, err := url.Parse(rawURL); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return "webhook"
+}
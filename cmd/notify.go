@@ -0,0 +1,258 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// notifyCmd represents the notify command
+var notifyCmd = &cobra.Command{
+	Use:   "notify",
+	Short: "Send notifications for your teams' matches",
+	Long: `The 'notify' command polls today's matches and sends a notification when
+one of your teams kicks off, scores or concedes, gets a red card, reaches
+half-time or finishes.
+
+Teams come from --team, or from the favourites in the config file. Events
+go to every notifier given; with none they are printed. Sent events are
+remembered, so restarting the command doesn't send them again.
+
+Shell commands get the event as JSON on stdin and in the environment
+variables SHARINGAN_EVENT_ID, SHARINGAN_EVENT_TYPE, SHARINGAN_EVENT_MESSAGE
+and SHARINGAN_MATCH_ID.
+
+Examples:
+  # Print Arsenal's goals, cards, kick-off, half-time and full-time
+  sharingan notify --team Arsenal
+
+  # Post to Slack and Discord
+  sharingan notify -t Arsenal --slack https://hooks.slack.com/services/... --discord https://discord.com/api/webhooks/...
+
+  # Only goals, to a JSON webhook and a desktop notification
+  sharingan notify -t ARS --events goal --webhook https://example.com/hook --exec 'notify-send "$SHARINGAN_EVENT_MESSAGE"'
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runNotify(cmd.Context())
+	},
+}
+
+var (
+	notifyTeams    []string
+	notifyEvents   []string
+	webhookURLs    []string
+	slackURLs      []string
+	discordURLs    []string
+	notifyCommands []string
+)
+
+func init() {
+	rootCmd.AddCommand(notifyCmd)
+
+	notifyCmd.Flags().StringSliceVarP(&notifyTeams, "team", "t", nil, "Teams to follow (default: favourites from the config)")
+	notifyCmd.Flags().StringSliceVar(&notifyEvents, "events", []string{eventGoal, eventRedCard, eventKickoff, eventHalfTime, eventFullTime}, "Event types to send")
+	notifyCmd.Flags().StringSliceVar(&webhookURLs, "webhook", nil, "URL to POST each event to as JSON")
+	notifyCmd.Flags().StringSliceVar(&slackURLs, "slack", nil, "Slack incoming webhook URL")
+	notifyCmd.Flags().StringSliceVar(&discordURLs, "discord", nil, "Discord webhook URL")
+	notifyCmd.Flags().StringArrayVar(&notifyCommands, "exec", nil, "Shell command to run for each event; it is stopped after 30s")
+	notifyCmd.Flags().DurationVar(&refreshInterval, "interval", 30*time.Second, "How often to poll the scoreboard")
+}
+
+// notifyState is kept between runs so a restart neither re-sends events nor
+// misses the ones that happened while it was down
+type notifyState struct {
+	// Matches is the last scoreboard snapshot seen
+	Matches map[string]apiMatch `json:"matches"`
+	// Sent records deliveries by deliveryKey
+	Sent map[string]time.Time `json:"sent"`
+	// Pending holds events some notifier hasn't accepted yet
+	Pending []matchEvent `json:"pending,omitempty"`
+}
+
+// Sent events are forgotten after sentRetention, and undeliverable ones
+// are given up after pendingRetention
+const (
+	sentRetention    = 72 * time.Hour
+	pendingRetention = time.Hour
+)
+
+func loadNotifyState(path string) (*notifyState, error) {
+	state := &notifyState{Matches: make(map[string]apiMatch), Sent: make(map[string]time.Time)}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if state.Matches == nil {
+		state.Matches = make(map[string]apiMatch)
+	}
+	if state.Sent == nil {
+		state.Sent = make(map[string]time.Time)
+	}
+	return state, nil
+}
+
+func (s *notifyState) save(path string) error {
+	for key, sent := range s.Sent {
+		if time.Since(sent) > sentRetention {
+			delete(s.Sent, key)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// buildNotifiers creates the notifiers selected by flags
+func buildNotifiers() []notifier {
+	var notifiers []notifier
+	for _, url := range webhookURLs {
+		notifiers = append(notifiers, webhookNotifier{url})
+	}
+	for _, url := range slackURLs {
+		notifiers = append(notifiers, slackNotifier{url})
+	}
+	for _, url := range discordURLs {
+		notifiers = append(notifiers, discordNotifier{url})
+	}
+	for _, command := range notifyCommands {
+		notifiers = append(notifiers, commandNotifier{command})
+	}
+	if len(notifiers) == 0 {
+		notifiers = append(notifiers, stdoutNotifier{})
+	}
+	return notifiers
+}
+
+func runNotify(ctx context.Context) error {
+	teams := notifyTeams
+	if len(teams) == 0 {
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		teams = cfg.Favourites
+	}
+	if len(teams) == 0 {
+		return errInvalidInput("no teams to follow: use --team or add favourites to the config")
+	}
+
+	path, err := statePath("notify-state.json")
+	if err != nil {
+		return err
+	}
+	state, err := loadNotifyState(path)
+	if err != nil {
+		return err
+	}
+
+	notifiers := buildNotifiers()
+	wanted := make(map[string]bool)
+	for _, kind := range notifyEvents {
+		wanted[strings.TrimSpace(kind)] = true
+	}
+
+	// Resume from the saved snapshot, so events that happened while we
+	// weren't running are still detected
+	detector := newEventDetector()
+	if len(state.Matches) > 0 {
+		detector.last = state.Matches
+		detector.primed = true
+	}
+
+	fmt.Printf("Following %s (%d notifier(s), polling every %s)\n", strings.Join(teams, ", "), len(notifiers), refreshInterval)
+
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		events, err := fetchTodayEvents(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil // interrupted
+			}
+			slog.Error("polling scoreboard failed", "err", err)
+		} else {
+			for _, e := range detector.update(events) {
+				if wanted[e.Type] && involvesAny(e, teams) {
+					state.Pending = append(state.Pending, e)
+				}
+			}
+			state.Matches = detector.last
+		}
+
+		state.Pending = deliverEvents(ctx, state, notifiers, state.Pending)
+		if err := state.save(path); err != nil {
+			slog.Warn("saving notification state failed", "err", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// deliverEvents sends each event to every notifier that hasn't had it yet
+// and returns the events that still need retrying
+func deliverEvents(ctx context.Context, state *notifyState, notifiers []notifier, events []matchEvent) []matchEvent {
+	var retry []matchEvent
+	for _, e := range events {
+		failed := false
+		for _, n := range notifiers {
+			key := deliveryKey(e, n)
+			if _, sent := state.Sent[key]; sent {
+				continue
+			}
+			if err := n.Notify(ctx, e); err != nil {
+				slog.Warn("notification failed", "notifier", n.Name(), "event", e.ID, "err", err)
+				failed = true
+				continue
+			}
+			state.Sent[key] = time.Now()
+		}
+		if failed && time.Since(e.Time) < pendingRetention {
+			retry = append(retry, e)
+		}
+	}
+	return retry
+}
+
+// deliveryKey identifies the delivery of an event to one notifier. The
+// notifier's settings are hashed so webhook URLs aren't written to disk.
+func deliveryKey(e matchEvent, n notifier) string {
+	h := fnv.New32a()
+	fmt.Fprintf(h, "%T%v", n, n)
+	return fmt.Sprintf("%s/%s-%08x", e.ID, n.Name(), h.Sum32())
+}
+
+// involvesAny reports whether an event involves one of the teams
+func involvesAny(e matchEvent, teams []string) bool {
+	for _, t := range teams {
+		if e.involves(strings.TrimSpace(t)) {
+			return true
+		}
+	}
+	return false
+}
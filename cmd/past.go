@@ -1,12 +1,9 @@
 package cmd
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"io"
-	"log"
-	"net/http"
-	"os"
+	"log/slog"
 	"strings"
 	"time"
 
@@ -33,8 +30,8 @@ Examples:
   # Get detailed match information
   sharingan past --detailed
 `,
-	Run: func(cmd *cobra.Command, args []string) {
-		fetchPastMatches()
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return fetchPastMatches(cmd.Context())
 	},
 }
 
@@ -49,21 +46,21 @@ func init() {
 }
 
 // fetchPastMatches retrieves match results from the ESPN API
-func fetchPastMatches() {
+func fetchPastMatches(ctx context.Context) error {
 	// Set date(s) for the query
 	startDate := date
 	if startDate == "" {
 		startDate = time.Now().AddDate(0, 0, -1).Format("2006-01-02")
 	}
+	startDateObj, err := time.Parse("2006-01-02", startDate)
+	if err != nil {
+		return errInvalidInput("invalid date %q: use YYYY-MM-DD", startDate)
+	}
 
 	// Calculate end date if range is specified
 	endDate := ""
 	if dateRange > 1 {
-		endDateObj, err := time.Parse("2006-01-02", startDate)
-		if err != nil {
-			log.Fatalf("Invalid date format: %v", err)
-		}
-		endDate = endDateObj.AddDate(0, 0, dateRange-1).Format("2006-01-02")
+		endDate = startDateObj.AddDate(0, 0, dateRange-1).Format("2006-01-02")
 	}
 
 	// Build URL based on date(s)
@@ -74,59 +71,28 @@ func fetchPastMatches() {
 		url = fmt.Sprintf("https://site.api.espn.com/apis/site/v2/sports/soccer/all/scoreboard?dates=%s", startDate)
 	}
 
-	client := &http.Client{Timeout: 30 * time.Second}
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		log.Fatalf("Error creating request: %v", err)
-	}
-
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/114.0.0.0 Safari/537.36")
-	req.Header.Set("Accept", "application/json")
-	req.Header.Set("Accept-Language", "en-US,en;q=0.9")
-
-	// Logging the request URL for debugging
-	fmt.Printf("Request URL: %s\n", url)
+	slog.Debug("fetching past matches", "url", url)
 
 	// Make the request
-	resp, err := client.Do(req)
+	body, err := fetchESPN(ctx, url)
 	if err != nil {
-		log.Fatalf("Error fetching data: %v", err)
-	}
-	defer resp.Body.Close()
-
-	// Read the response body
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		log.Fatalf("Error reading response: %v", err)
-	}
-
-	// Log the raw response if debugging
-	if os.Getenv("DEBUG") == "true" {
-		if err := os.WriteFile("espn_past_response.json", body, 0644); err != nil {
-			log.Printf("Warning: Failed to save response to file: %v", err)
-		} else {
-			fmt.Println("Saved raw response to espn_past_response.json")
-		}
+		return err
 	}
 
 	// If the format is JSON, output the raw response
 	if format == "json" {
 		fmt.Println(string(body))
-		return
+		return nil
 	}
 
 	// Parse the JSON response
 	var espnData ESPNResponse
-	err = json.Unmarshal(body, &espnData)
-	if err != nil {
-		log.Printf("Error parsing JSON: %v", err)
-		fmt.Printf("Response sample: %s\n", string(body[:min(1000, len(body))]))
-		return
+	if err := decodeESPN("scoreboard", body, &espnData); err != nil {
+		return err
 	}
 
-	// Debug: print the raw events to inspect their structure
 	for _, event := range espnData.Events {
-		fmt.Printf("Event: %s | Status: %+v\n", event.Name, event.Status.Type.State)
+		slog.Debug("event", "id", event.ID, "name", event.Name, "state", event.Status.Type.State)
 	}
 
 	// Filter completed matches
@@ -145,7 +111,7 @@ func fetchPastMatches() {
 	// If no completed matches are found, print a message
 	if len(completedMatches) == 0 {
 		fmt.Println("No completed matches found for the selected filters.")
-		return
+		return nil
 	}
 
 	// Print header for completed matches
@@ -154,6 +120,7 @@ func fetchPastMatches() {
 	fmt.Println("=================================")
 
 	// Display the matches
-	displayMatches(completedMatches, "completed")
+	displayMatches(ctx, completedMatches, "completed")
 	fmt.Printf("\nTotal completed matches: %d\n", len(completedMatches))
+	return nil
 }
@@ -0,0 +1,325 @@
+package cmd
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// poissonModel predicts scorelines from per-team attack and defence
+// strengths. The expected goals of each side are
+//
+//	home: base * homeAdvantage * attack[home] * defence[away]
+//	away: base * attack[away] * defence[home]
+//
+// and the two goal counts are treated as independent Poisson variables.
+type poissonModel struct {
+	Base          float64            `json:"base"`
+	HomeAdvantage float64            `json:"homeAdvantage"`
+	Attack        map[string]float64 `json:"attack"`
+	Defence       map[string]float64 `json:"defence"`
+	Teams         map[string]Team    `json:"-"`
+}
+
+// poissonHalfLife controls how quickly old results lose weight when fitting
+const poissonHalfLife = 180 * 24 * time.Hour
+
+// maxGoals bounds the scoreline grid; the probability mass beyond it is negligible
+const maxGoals = 10
+
+// fitPoissonModel estimates team strengths from results played before asOf.
+// More recent results weigh more, and every team is shrunk towards the
+// league average so that a handful of matches can't produce extreme values.
+func fitPoissonModel(results []matchResult, asOf time.Time) *poissonModel {
+	model := &poissonModel{
+		Base:          1,
+		HomeAdvantage: 1,
+		Attack:        make(map[string]float64),
+		Defence:       make(map[string]float64),
+		Teams:         make(map[string]Team),
+	}
+
+	type weighted struct {
+		matchResult
+		weight float64
+	}
+
+	var games []weighted
+	var homeGoals, awayGoals, totalWeight float64
+	for _, m := range results {
+		if !m.Date.Before(asOf) {
+			continue
+		}
+		age := asOf.Sub(m.Date)
+		w := math.Pow(0.5, float64(age)/float64(poissonHalfLife))
+		games = append(games, weighted{m, w})
+
+		homeGoals += w * float64(m.HomeGoals)
+		awayGoals += w * float64(m.AwayGoals)
+		totalWeight += w
+
+		model.Teams[m.Home.ID] = m.Home
+		model.Teams[m.Away.ID] = m.Away
+		model.Attack[m.Home.ID], model.Attack[m.Away.ID] = 1, 1
+		model.Defence[m.Home.ID], model.Defence[m.Away.ID] = 1, 1
+	}
+
+	if totalWeight == 0 || awayGoals == 0 {
+		return model
+	}
+	model.Base = awayGoals / totalWeight
+	model.HomeAdvantage = homeGoals / awayGoals
+
+	// Prior weight, in matches, pulling every team towards average strength
+	const prior = 2.0
+
+	for iter := 0; iter < 50; iter++ {
+		scored := make(map[string]float64)
+		conceded := make(map[string]float64)
+		expFor := make(map[string]float64)
+		expAgainst := make(map[string]float64)
+
+		for _, g := range games {
+			h, a := g.Home.ID, g.Away.ID
+			scored[h] += g.weight * float64(g.HomeGoals)
+			scored[a] += g.weight * float64(g.AwayGoals)
+			conceded[h] += g.weight * float64(g.AwayGoals)
+			conceded[a] += g.weight * float64(g.HomeGoals)
+
+			expFor[h] += g.weight * model.Base * model.HomeAdvantage * model.Defence[a]
+			expFor[a] += g.weight * model.Base * model.Defence[h]
+			expAgainst[h] += g.weight * model.Base * model.Attack[a]
+			expAgainst[a] += g.weight * model.Base * model.HomeAdvantage * model.Attack[h]
+		}
+
+		var attackSum float64
+		for id := range model.Attack {
+			model.Attack[id] = (scored[id] + prior*model.Base) / (expFor[id] + prior*model.Base)
+			attackSum += model.Attack[id]
+		}
+		// Attack and defence are only identified up to a common factor,
+		// so keep the average attack at 1
+		mean := attackSum / float64(len(model.Attack))
+		for id := range model.Attack {
+			model.Attack[id] /= mean
+		}
+		for id := range model.Defence {
+			model.Defence[id] = (conceded[id] + prior*model.Base) / (expAgainst[id] + prior*model.Base)
+		}
+
+		var homeScored, homeExpected float64
+		for _, g := range games {
+			homeScored += g.weight * float64(g.HomeGoals)
+			homeExpected += g.weight * model.Base * model.Attack[g.Home.ID] * model.Defence[g.Away.ID]
+		}
+		if homeExpected > 0 {
+			model.HomeAdvantage = homeScored / homeExpected
+		}
+	}
+
+	return model
+}
+
+// strength returns a team's attack and defence, defaulting to average for unknown teams
+func (m *poissonModel) strength(teamID string) (attack, defence float64) {
+	attack, ok := m.Attack[teamID]
+	if !ok {
+		attack = 1
+	}
+	defence, ok = m.Defence[teamID]
+	if !ok {
+		defence = 1
+	}
+	return attack, defence
+}
+
+// expectedGoals returns the goal expectations of both sides
+func (m *poissonModel) expectedGoals(homeID, awayID string) (home, away float64) {
+	homeAttack, homeDefence := m.strength(homeID)
+	awayAttack, awayDefence := m.strength(awayID)
+	home = m.Base * m.HomeAdvantage * homeAttack * awayDefence
+	away = m.Base * awayAttack * homeDefence
+	return home, away
+}
+
+// scoreline is the probability of one exact result
+type scoreline struct {
+	Home        int     `json:"home"`
+	Away        int     `json:"away"`
+	Probability float64 `json:"probability"`
+}
+
+// matchPrediction holds the outcome probabilities of a fixture
+type matchPrediction struct {
+	Home          Team        `json:"home"`
+	Away          Team        `json:"away"`
+	ExpectedHome  float64     `json:"expectedHomeGoals"`
+	ExpectedAway  float64     `json:"expectedAwayGoals"`
+	HomeWin       float64     `json:"homeWin"`
+	Draw          float64     `json:"draw"`
+	AwayWin       float64     `json:"awayWin"`
+	LikelyResults []scoreline `json:"likelyResults"`
+}
+
+// predict computes outcome and scoreline probabilities for a fixture
+func (m *poissonModel) predict(home, away Team) matchPrediction {
+	lambdaHome, lambdaAway := m.expectedGoals(home.ID, away.ID)
+	p := matchPrediction{Home: home, Away: away, ExpectedHome: lambdaHome, ExpectedAway: lambdaAway}
+
+	var grid []scoreline
+	var total float64
+	for h := 0; h <= maxGoals; h++ {
+		for a := 0; a <= maxGoals; a++ {
+			prob := poissonPMF(h, lambdaHome) * poissonPMF(a, lambdaAway)
+			total += prob
+			grid = append(grid, scoreline{Home: h, Away: a, Probability: prob})
+		}
+	}
+
+	for i := range grid {
+		grid[i].Probability /= total
+		switch {
+		case grid[i].Home > grid[i].Away:
+			p.HomeWin += grid[i].Probability
+		case grid[i].Home < grid[i].Away:
+			p.AwayWin += grid[i].Probability
+		default:
+			p.Draw += grid[i].Probability
+		}
+	}
+
+	sort.Slice(grid, func(i, j int) bool { return grid[i].Probability > grid[j].Probability })
+	p.LikelyResults = grid[:5]
+	return p
+}
+
+// poissonPMF is the probability of exactly k events with mean lambda
+func poissonPMF(k int, lambda float64) float64 {
+	if lambda <= 0 {
+		// No goals expected: log(0) would make the formula NaN
+		if k == 0 {
+			return 1
+		}
+		return 0
+	}
+	logP := float64(k)*math.Log(lambda) - lambda
+	for i := 2; i <= k; i++ {
+		logP -= math.Log(float64(i))
+	}
+	return math.Exp(logP)
+}
+
+// calibrationBucket compares predicted and observed frequencies for one
+// range of predicted probabilities
+type calibrationBucket struct {
+	Lower     float64 `json:"lower"`
+	Upper     float64 `json:"upper"`
+	Count     int     `json:"count"`
+	Predicted float64 `json:"predicted"`
+	Observed  float64 `json:"observed"`
+}
+
+// backtestReport summarises how well the model predicted past results
+type backtestReport struct {
+	Matches       int                 `json:"matches"`
+	Brier         float64             `json:"brier"`
+	BaselineBrier float64             `json:"baselineBrier"`
+	LogLoss       float64             `json:"logLoss"`
+	Accuracy      float64             `json:"accuracy"`
+	Calibration   []calibrationBucket `json:"calibration"`
+}
+
+// backtestPoisson replays the results in order, predicting every match with
+// a model fitted only on what was known the day before. The first warmup
+// matches are used for fitting only.
+func backtestPoisson(results []matchResult, warmup int) backtestReport {
+	sortResults(results)
+
+	var report backtestReport
+	buckets := make([]calibrationBucket, 10)
+	for i := range buckets {
+		buckets[i].Lower = float64(i) / 10
+		buckets[i].Upper = float64(i+1) / 10
+	}
+
+	var model *poissonModel
+	var fittedFor time.Time
+	var outcomeCounts [3]float64
+
+	for i, m := range results {
+		if i < warmup {
+			continue
+		}
+
+		day := m.Date.Truncate(24 * time.Hour)
+		if model == nil || !day.Equal(fittedFor) {
+			model = fitPoissonModel(results[:i], day)
+			fittedFor = day
+		}
+
+		p := model.predict(m.Home, m.Away)
+		probs := [3]float64{p.HomeWin, p.Draw, p.AwayWin}
+
+		actual := 1
+		switch {
+		case m.HomeGoals > m.AwayGoals:
+			actual = 0
+		case m.HomeGoals < m.AwayGoals:
+			actual = 2
+		}
+
+		best := 0
+		for k := range probs {
+			observed := 0.0
+			if k == actual {
+				observed = 1
+			}
+			report.Brier += (probs[k] - observed) * (probs[k] - observed)
+
+			b := int(probs[k] * 10)
+			if b > 9 {
+				b = 9
+			}
+			buckets[b].Count++
+			buckets[b].Predicted += probs[k]
+			buckets[b].Observed += observed
+
+			if probs[k] > probs[best] {
+				best = k
+			}
+		}
+
+		report.LogLoss -= math.Log(math.Max(probs[actual], 1e-12))
+		if best == actual {
+			report.Accuracy++
+		}
+		outcomeCounts[actual]++
+		report.Matches++
+	}
+
+	if report.Matches == 0 {
+		return report
+	}
+
+	n := float64(report.Matches)
+	report.Brier /= n
+	report.LogLoss /= n
+	report.Accuracy /= n
+
+	// The baseline always predicts the observed outcome frequencies
+	for k := range outcomeCounts {
+		rate := outcomeCounts[k] / n
+		report.BaselineBrier += rate * (1 - rate)
+	}
+
+	for _, b := range buckets {
+		if b.Count == 0 {
+			continue
+		}
+		b.Predicted /= float64(b.Count)
+		b.Observed /= float64(b.Count)
+		report.Calibration = append(report.Calibration, b)
+	}
+
+	return report
+}
@@ -0,0 +1,233 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var predictCmd = &cobra.Command{
+	Use:   "predict [matchId | homeTeam awayTeam]",
+	Short: "Predict the outcome of a fixture",
+	Long: `The 'predict' command estimates win/draw/loss probabilities and the most
+likely scorelines of a fixture. Attack and defence strengths are fitted from
+the league's recent results with a Poisson goal model.
+
+Examples:
+  # Predict a fixture by its ESPN match ID
+  sharingan predict --league eng.1 704512
+
+  # Predict a fixture between two teams (home team first)
+  sharingan predict --league eng.1 Arsenal Chelsea
+
+  # Check how well-calibrated the model has been on past results
+  sharingan predict --league eng.1 --backtest
+`,
+	Args: cobra.MaximumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runPredict(cmd.Context(), args)
+	},
+}
+
+var backtest bool
+
+func init() {
+	rootCmd.AddCommand(predictCmd)
+
+	predictCmd.Flags().StringVarP(&league, "league", "l", "", "League slug (e.g. eng.1, esp.1)")
+	predictCmd.MarkFlagRequired("league")
+	predictCmd.Flags().IntVar(&historyDays, "days", 365, "Number of days of results to fit the model on")
+	predictCmd.Flags().BoolVar(&backtest, "backtest", false, "Replay past results and report calibration")
+	predictCmd.Flags().StringVarP(&format, "format", "f", "pretty", "Output format (pretty, json)")
+}
+
+func runPredict(ctx context.Context, args []string) error {
+	now := time.Now()
+	results, err := fetchLeagueResults(ctx, league, now.AddDate(0, 0, -historyDays), now)
+	if err != nil {
+		return fmt.Errorf("fetching results: %w", err)
+	}
+
+	if backtest {
+		report := backtestPoisson(results, len(results)/4)
+		if format == "json" {
+			out, _ := json.MarshalIndent(report, "", "  ")
+			fmt.Println(string(out))
+			return nil
+		}
+		displayBacktest(report)
+		return nil
+	}
+
+	model := fitPoissonModel(results, now)
+
+	var home, away Team
+	switch len(args) {
+	case 1:
+		home, away, err = fetchFixtureTeams(ctx, league, args[0])
+		if err != nil {
+			return fmt.Errorf("fetching match: %w", err)
+		}
+	case 2:
+		var ok bool
+		if home, ok = model.findTeam(args[0]); !ok {
+			return errNotFound("team '%s' not found in %s results", args[0], league)
+		}
+		if away, ok = model.findTeam(args[1]); !ok {
+			return errNotFound("team '%s' not found in %s results", args[1], league)
+		}
+	default:
+		return errInvalidInput("please provide a match ID or two team names, or use --backtest")
+	}
+
+	prediction := model.predict(home, away)
+	if format == "json" {
+		out, _ := json.MarshalIndent(prediction, "", "  ")
+		fmt.Println(string(out))
+		return nil
+	}
+
+	title := color.New(color.FgCyan, color.Bold).SprintFunc()
+	fmt.Printf("\n%s\n", title(fmt.Sprintf("%s vs %s", home.DisplayName, away.DisplayName)))
+	fmt.Println("=================================")
+	displayPrediction(prediction)
+	return nil
+}
+
+// fetchFixtureTeams looks up the home and away team of a match by ID
+func fetchFixtureTeams(ctx context.Context, leagueSlug, matchID string) (home, away Team, err error) {
+	summary, err := fetchMatchSummary(ctx, leagueSlug, matchID)
+	if err != nil {
+		return home, away, err
+	}
+	if len(summary.Header.Competitions) == 0 {
+		return home, away, errNotFound("match %s not found", matchID)
+	}
+
+	h, a, ok := homeAndAway(summary.Header.Competitions[0])
+	if !ok {
+		return home, away, fmt.Errorf("match %s has no competitors", matchID)
+	}
+	return h.Team, a.Team, nil
+}
+
+// findTeam matches a name or abbreviation against the teams the model was
+// fitted on. An exact match wins; otherwise the shortest name containing it,
+// so the same input always picks the same team.
+func (m *poissonModel) findTeam(name string) (Team, bool) {
+	searchTerm := strings.ToLower(name)
+	var candidates []Team
+	for _, t := range m.Teams {
+		if strings.EqualFold(t.Abbreviation, searchTerm) || strings.EqualFold(t.DisplayName, searchTerm) {
+			return t, true
+		}
+		if strings.Contains(strings.ToLower(t.DisplayName), searchTerm) {
+			candidates = append(candidates, t)
+		}
+	}
+	if len(candidates) == 0 {
+		return Team{}, false
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i].DisplayName, candidates[j].DisplayName
+		if len(a) != len(b) {
+			return len(a) < len(b)
+		}
+		return a < b
+	})
+	return candidates[0], true
+}
+
+// displayPrediction prints outcome probabilities and the likeliest scorelines
+func displayPrediction(p matchPrediction) {
+	fmt.Printf("Expected goals: %.2f - %.2f\n", p.ExpectedHome, p.ExpectedAway)
+	fmt.Printf("Home win: %4.1f%%  Draw: %4.1f%%  Away win: %4.1f%%\n", p.HomeWin*100, p.Draw*100, p.AwayWin*100)
+
+	var likely []string
+	for _, s := range p.LikelyResults[:3] {
+		likely = append(likely, fmt.Sprintf("%d-%d (%.0f%%)", s.Home, s.Away, s.Probability*100))
+	}
+	fmt.Printf("Likely scores: %s\n", strings.Join(likely, ", "))
+}
+
+// displayBacktest prints the accuracy and calibration of past predictions
+func displayBacktest(r backtestReport) {
+	title := color.New(color.FgCyan, color.Bold).SprintFunc()
+	fmt.Printf("\n%s\n", title(fmt.Sprintf("BACKTEST: %s", strings.ToUpper(league))))
+	fmt.Println("=================================")
+
+	if r.Matches == 0 {
+		fmt.Println("Not enough results to backtest.")
+		return
+	}
+
+	fmt.Printf("Matches predicted: %d\n", r.Matches)
+	fmt.Printf("Brier score: %.4f (base rates: %.4f, lower is better)\n", r.Brier, r.BaselineBrier)
+	fmt.Printf("Log loss: %.4f\n", r.LogLoss)
+	fmt.Printf("Favourite won: %.1f%%\n", r.Accuracy*100)
+
+	fmt.Println("\nCalibration")
+	fmt.Printf("%-12s %7s %10s %9s\n", "Predicted", "Count", "Mean pred", "Observed")
+	for _, b := range r.Calibration {
+		fmt.Printf("%3.0f%% - %3.0f%% %7d %9.1f%% %8.1f%%\n", b.Lower*100, b.Upper*100, b.Count, b.Predicted*100, b.Observed*100)
+	}
+}
+
+// predictionModels caches fitted models per league for the lifetime of a
+// command. Summaries are built concurrently, so each league is fitted by one
+// caller while the others wait; a failed fit is retried after a pause.
+var predictionModels = struct {
+	mu      sync.Mutex
+	leagues map[string]*leagueModel
+}{leagues: make(map[string]*leagueModel)}
+
+// predictionRetry is how long a league whose model couldn't be fitted waits
+// before the next attempt
+const predictionRetry = time.Minute
+
+// leagueModel is a league's fitted model, or nil until a fit succeeds
+type leagueModel struct {
+	mu      sync.Mutex
+	model   *poissonModel
+	retryAt time.Time
+}
+
+// predictionFor fits (or reuses) the model of a league and predicts a fixture.
+// It returns false when the league can't be determined or its results can't
+// be fetched, so callers can simply skip the prediction.
+func predictionFor(ctx context.Context, leagueSlug string, home, away Team) (matchPrediction, bool) {
+	if leagueSlug == "" {
+		return matchPrediction{}, false
+	}
+
+	predictionModels.mu.Lock()
+	slot, ok := predictionModels.leagues[leagueSlug]
+	if !ok {
+		slot = &leagueModel{}
+		predictionModels.leagues[leagueSlug] = slot
+	}
+	predictionModels.mu.Unlock()
+
+	slot.mu.Lock()
+	if now := time.Now(); slot.model == nil && !now.Before(slot.retryAt) {
+		results, err := fetchLeagueResults(ctx, leagueSlug, now.AddDate(0, 0, -365), now)
+		if err == nil && len(results) > 0 {
+			slot.model = fitPoissonModel(results, now)
+		} else {
+			slot.retryAt = now.Add(predictionRetry)
+		}
+	}
+	model := slot.model
+	slot.mu.Unlock()
+	if model == nil {
+		return matchPrediction{}, false
+	}
+	return model.predict(home, away), true
+}
@@ -0,0 +1,275 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// provider supplies match data to long-running modes such as serve. It
+// exists so those modes can share one upstream cache and be pointed at
+// something other than ESPN.
+type provider interface {
+	Name() string
+	// Scoreboard returns the matches of a league on a date (YYYYMMDD); an
+	// empty date means today
+	Scoreboard(ctx context.Context, league, date string) ([]Event, error)
+	Team(ctx context.Context, id string) (teamDetail, error)
+	Standings(ctx context.Context, league string) ([]*standingsRow, error)
+	// ComputedStandings derives the table from the season's results
+	ComputedStandings(ctx context.Context, league string) ([]*standingsRow, error)
+	Match(ctx context.Context, league, id string) (Event, error)
+}
+
+// teamDetail mirrors ESPN's team endpoint
+type teamDetail struct {
+	Team struct {
+		Team
+		StandingSummary string  `json:"standingSummary"`
+		NextEvent       []Event `json:"nextEvent"`
+	} `json:"team"`
+}
+
+// espnProvider reads from ESPN's site API, or a mirror of it, through a
+// shared cache
+type espnProvider struct {
+	name          string
+	base          string
+	standingsBase string
+	fetch         func(context.Context, string) ([]byte, error)
+	cache         *responseCache
+}
+
+func newESPNProvider(cacheTTL time.Duration) *espnProvider {
+	return &espnProvider{
+		name:          "espn",
+		base:          espnSoccerBase,
+		standingsBase: espnStandingsBase,
+		fetch:         fetchESPN,
+		cache:         newResponseCache(cacheTTL),
+	}
+}
+
+// newMirrorProvider reads from an ESPN-compatible mirror such as
+// https://site.web.api.espn.com. It bypasses ESPN's circuit breaker and
+// fallback, so it stays an independent source.
+func newMirrorProvider(mirror string, cacheTTL time.Duration) *espnProvider {
+	mirror = strings.TrimSuffix(mirror, "/")
+	return &espnProvider{
+		name:          hostOf(mirror),
+		base:          mirror + strings.TrimPrefix(espnSoccerBase, espnHost),
+		standingsBase: mirror + strings.TrimPrefix(espnStandingsBase, espnHost),
+		fetch:         fetchUpstream,
+		cache:         newResponseCache(cacheTTL),
+	}
+}
+
+func (p *espnProvider) Name() string {
+	return p.name
+}
+
+func (p *espnProvider) get(ctx context.Context, url string, v interface{}) error {
+	body, err := p.cache.get(ctx, url, p.fetch)
+	if err != nil {
+		return err
+	}
+	return decodeESPN(url, body, v)
+}
+
+func (p *espnProvider) Scoreboard(ctx context.Context, league, date string) ([]Event, error) {
+	url := fmt.Sprintf("%s/%s/scoreboard", p.base, defaultIfEmpty(league, "all"))
+	if date != "" {
+		url += "?dates=" + date
+	}
+
+	var data ESPNResponse
+	if err := p.get(ctx, url, &data); err != nil {
+		return nil, err
+	}
+	return data.Events, nil
+}
+
+func (p *espnProvider) Team(ctx context.Context, id string) (teamDetail, error) {
+	var data teamDetail
+	err := p.get(ctx, fmt.Sprintf("%s/all/teams/%s", p.base, id), &data)
+	if err == nil && data.Team.ID == "" {
+		err = errNotFound("team %s not found", id)
+	}
+	return data, err
+}
+
+func (p *espnProvider) Standings(ctx context.Context, league string) ([]*standingsRow, error) {
+	body, err := p.cache.get(ctx, fmt.Sprintf("%s/%s/standings", p.standingsBase, league), p.fetch)
+	if err != nil {
+		return nil, err
+	}
+	return parseProviderStandings(league, body)
+}
+
+func (p *espnProvider) ComputedStandings(ctx context.Context, league string) ([]*standingsRow, error) {
+	// Computing a table takes a whole season of results, so the table
+	// itself is cached, under a key that can't clash with a URL
+	body, err := p.cache.get(ctx, "computed-standings:"+league, func(ctx context.Context, _ string) ([]byte, error) {
+		rows, err := fetchComputedStandings(ctx, league, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+	var rows []*standingsRow
+	if err := json.Unmarshal(body, &rows); err != nil {
+		return nil, &decodeError{league + " computed standings", err}
+	}
+	return rows, nil
+}
+
+func (p *espnProvider) Match(ctx context.Context, league, id string) (Event, error) {
+	var summary matchSummary
+	if err := p.get(ctx, fmt.Sprintf("%s/%s/summary?event=%s", p.base, defaultIfEmpty(league, "all"), id), &summary); err != nil {
+		return Event{}, err
+	}
+	if len(summary.Header.Competitions) == 0 {
+		return Event{}, errNotFound("match %s not found", id)
+	}
+
+	c := summary.Header.Competitions[0]
+	return Event{
+		ID:           summary.Header.ID,
+		Date:         c.Date,
+		Status:       c.Status,
+		Competitions: []Competition{c},
+		League:       summary.Header.League,
+	}, nil
+}
+
+// responseCache keeps upstream responses for a short time and collapses
+// concurrent requests for the same URL into a single upstream call
+type responseCache struct {
+	ttl time.Duration
+
+	mu        sync.Mutex
+	entries   map[string]cacheEntry
+	inflight  map[string]*cacheCall
+	lastSweep time.Time
+}
+
+// cacheEntry and cacheCall note whether the body came from a fallback, so
+// every caller served it can be told
+type cacheEntry struct {
+	body     []byte
+	fetched  time.Time
+	fallback bool
+}
+
+type cacheCall struct {
+	done     chan struct{}
+	body     []byte
+	err      error
+	fallback bool
+}
+
+func newResponseCache(ttl time.Duration) *responseCache {
+	return &responseCache{
+		ttl:      ttl,
+		entries:  make(map[string]cacheEntry),
+		inflight: make(map[string]*cacheCall),
+	}
+}
+
+// get returns a cached response or fetches it, sharing the fetch with any
+// other caller asking for the same URL at the same time. The shared fetch
+// runs on its own context, bounded by requestTimeout, so one caller going
+// away doesn't fail the others; a caller whose ctx ends just stops waiting.
+func (c *responseCache) get(ctx context.Context, url string, fetch func(context.Context, string) ([]byte, error)) ([]byte, error) {
+	c.mu.Lock()
+	if entry, ok := c.entries[url]; ok && time.Since(entry.fetched) < c.ttl {
+		c.mu.Unlock()
+		cacheRequests.inc("hit")
+		if entry.fallback {
+			markFallback(ctx)
+		}
+		return entry.body, nil
+	}
+	call, shared := c.inflight[url]
+	if !shared {
+		call = &cacheCall{done: make(chan struct{})}
+		c.inflight[url] = call
+	}
+	c.mu.Unlock()
+
+	if shared {
+		cacheRequests.inc("shared")
+	} else {
+		cacheRequests.inc("miss")
+		go c.fetch(context.WithoutCancel(ctx), url, call, fetch)
+	}
+
+	select {
+	case <-call.done:
+		if call.fallback {
+			markFallback(ctx)
+		}
+		return call.body, call.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (c *responseCache) fetch(ctx context.Context, url string, call *cacheCall, fetch func(context.Context, string) ([]byte, error)) {
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+	// The fetch gets its own source, as ctx carries the first caller's
+	src := &fetchSource{}
+	call.body, call.err = fetch(withFetchSource(ctx, src), url)
+	call.fallback = src.fallback.Load()
+
+	c.mu.Lock()
+	delete(c.inflight, url)
+	if call.err == nil && c.ttl > 0 {
+		c.entries[url] = cacheEntry{body: call.body, fetched: time.Now(), fallback: call.fallback}
+		c.sweep()
+	}
+	c.mu.Unlock()
+	close(call.done)
+}
+
+// sweep drops expired entries, at most once per ttl, so a long-running
+// server doesn't keep every URL it ever fetched. c.mu must be held.
+func (c *responseCache) sweep() {
+	now := time.Now()
+	if now.Sub(c.lastSweep) < c.ttl {
+		return
+	}
+	c.lastSweep = now
+	for url, entry := range c.entries {
+		if now.Sub(entry.fetched) >= c.ttl {
+			delete(c.entries, url)
+		}
+	}
+}
+
+// eventMatchesLeague applies the --league style filter used by live and past
+func eventMatchesLeague(event Event, filter string) bool {
+	if filter == "" {
+		return true
+	}
+	if slugs := leagueSlugs(filter); len(slugs) > 1 {
+		for _, slug := range slugs {
+			if strings.EqualFold(event.League.Slug, slug) {
+				return true
+			}
+		}
+		return false
+	}
+	if strings.Contains(strings.ToLower(event.Name), strings.ToLower(filter)) {
+		return true
+	}
+	return event.League.Abbreviation != "" && (strings.EqualFold(event.League.Abbreviation, filter) ||
+		strings.EqualFold(event.League.Name, filter) || strings.EqualFold(event.League.Slug, filter))
+}
@@ -0,0 +1,379 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testBroker accepts connections on a local port and hands each to serve
+func testBroker(t *testing.T, serve func(conn net.Conn)) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				conn.SetDeadline(time.Now().Add(5 * time.Second))
+				serve(conn)
+			}()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+// mqttPacket is a packet as read by the test broker
+type mqttPacket struct {
+	header byte
+	body   []byte
+}
+
+func readMQTTPacket(r *bufio.Reader) (mqttPacket, error) {
+	header, err := r.ReadByte()
+	if err != nil {
+		return mqttPacket{}, err
+	}
+	length, multiplier := 0, 1
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return mqttPacket{}, err
+		}
+		length += int(b&0x7F) * multiplier
+		if b&0x80 == 0 {
+			break
+		}
+		multiplier *= 128
+	}
+	body := make([]byte, length)
+	_, err = io.ReadFull(r, body)
+	return mqttPacket{header, body}, err
+}
+
+// readMQTTString reads a length-prefixed string off the front of b
+func readMQTTString(b []byte) (string, []byte) {
+	n := int(binary.BigEndian.Uint16(b))
+	return string(b[2 : 2+n]), b[2+n:]
+}
+
+func TestMQTTConnectAndPublish(t *testing.T) {
+	packets := make(chan mqttPacket, 10)
+	addr := testBroker(t, func(conn net.Conn) {
+		r := bufio.NewReader(conn)
+		connect, err := readMQTTPacket(r)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		packets <- connect
+		conn.Write([]byte{mqttConnack, 2, 0, 0})
+		for {
+			p, err := readMQTTPacket(r)
+			if err != nil {
+				close(packets)
+				return
+			}
+			packets <- p
+		}
+	})
+
+	c, err := dialMQTT("mqtt://user:secret@"+addr, "client-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Long enough to need a two-byte remaining length
+	long := bytes.Repeat([]byte("x"), 300)
+	if err := c.publish("sharingan/eng.1/401/goal", long, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.publish("sharingan/eng.1/401/state", []byte("{}"), true); err != nil {
+		t.Fatal(err)
+	}
+	c.close()
+
+	connect := <-packets
+	if connect.header != mqttConnect {
+		t.Fatalf("first packet %#x, want CONNECT", connect.header)
+	}
+	protocol, rest := readMQTTString(connect.body)
+	if protocol != "MQTT" || rest[0] != 4 {
+		t.Errorf("protocol %q level %d, want MQTT 4", protocol, rest[0])
+	}
+	if flags := rest[1]; flags != 0xC2 {
+		t.Errorf("connect flags %#x, want username, password and clean session", flags)
+	}
+	clientID, rest := readMQTTString(rest[4:])
+	user, rest := readMQTTString(rest)
+	password, _ := readMQTTString(rest)
+	if clientID != "client-1" || user != "user" || password != "secret" {
+		t.Errorf("connect payload %q %q %q", clientID, user, password)
+	}
+
+	goal := <-packets
+	topic, payload := readMQTTString(goal.body)
+	if goal.header != mqttPublish || topic != "sharingan/eng.1/401/goal" || !bytes.Equal(payload, long) {
+		t.Errorf("publish: header %#x topic %q, %d byte payload", goal.header, topic, len(payload))
+	}
+	state := <-packets
+	if topic, _ := readMQTTString(state.body); state.header != mqttPublish|0x01 || topic != "sharingan/eng.1/401/state" {
+		t.Errorf("retained publish: header %#x topic %q", state.header, topic)
+	}
+	if p := <-packets; p.header != mqttDisconnect {
+		t.Errorf("last packet %#x, want DISCONNECT", p.header)
+	}
+}
+
+func TestMQTTConnectRefused(t *testing.T) {
+	addr := testBroker(t, func(conn net.Conn) {
+		readMQTTPacket(bufio.NewReader(conn))
+		conn.Write([]byte{mqttConnack, 2, 0, 5}) // not authorized
+	})
+	if _, err := dialMQTT("mqtt://"+addr, "client-1"); err == nil || !strings.Contains(err.Error(), "code 5") {
+		t.Errorf("got %v, want a refused connection", err)
+	}
+}
+
+// natsBroker is a NATS server that records what it's published, and can
+// drop its connections
+type natsBroker struct {
+	mu       sync.Mutex
+	messages []string // subject and payload
+	connects []map[string]interface{}
+	conns    []net.Conn
+	pongs    int
+	deny     string // subject whose publishes are rejected
+}
+
+func (b *natsBroker) serve(conn net.Conn) {
+	b.mu.Lock()
+	b.conns = append(b.conns, conn)
+	b.mu.Unlock()
+
+	fmt.Fprint(conn, "INFO {\"server_id\":\"test\"}\r\n")
+	r := bufio.NewReader(conn)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "CONNECT "):
+			var options map[string]interface{}
+			json.Unmarshal([]byte(strings.TrimPrefix(line, "CONNECT ")), &options)
+			b.mu.Lock()
+			b.connects = append(b.connects, options)
+			b.mu.Unlock()
+		case line == "PING":
+			fmt.Fprint(conn, "PONG\r\n")
+			// Check the client answers the server's pings too
+			fmt.Fprint(conn, "PING\r\n")
+		case line == "PONG":
+			b.mu.Lock()
+			b.pongs++
+			b.mu.Unlock()
+		case strings.HasPrefix(line, "PUB "):
+			fields := strings.Fields(line)
+			n, _ := strconv.Atoi(fields[len(fields)-1])
+			payload := make([]byte, n+2)
+			if _, err := io.ReadFull(r, payload); err != nil {
+				return
+			}
+			if fields[1] == b.deny {
+				// The PING shows when the client has read past the -ERR
+				fmt.Fprintf(conn, "-ERR 'Permissions Violation for Publish to \"%s\"'\r\nPING\r\n", b.deny)
+				continue
+			}
+			b.mu.Lock()
+			b.messages = append(b.messages, fields[1]+" "+string(payload[:n]))
+			b.mu.Unlock()
+		}
+	}
+}
+
+func (b *natsBroker) received() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]string{}, b.messages...)
+}
+
+func TestNATSConnectAndPublish(t *testing.T) {
+	broker := &natsBroker{}
+	addr := testBroker(t, broker.serve)
+
+	c, err := dialNATS("nats://user:secret@" + addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.close()
+	if err := c.publish("sharingan.eng_1.401.goal", []byte(`{"type":"goal"}`), false); err != nil {
+		t.Fatal(err)
+	}
+
+	waitFor(t, "the message", func() bool { return len(broker.received()) == 1 })
+	if got := broker.received()[0]; got != `sharingan.eng_1.401.goal {"type":"goal"}` {
+		t.Errorf("received %q", got)
+	}
+	broker.mu.Lock()
+	options := broker.connects[0]
+	broker.mu.Unlock()
+	if options["user"] != "user" || options["pass"] != "secret" || options["verbose"] != false {
+		t.Errorf("CONNECT options %v", options)
+	}
+	waitFor(t, "the PONG", func() bool {
+		broker.mu.Lock()
+		defer broker.mu.Unlock()
+		return broker.pongs > 0
+	})
+}
+
+func TestNATSPermissionErrorKeepsConnection(t *testing.T) {
+	broker := &natsBroker{deny: "denied"}
+	addr := testBroker(t, broker.serve)
+
+	c, err := dialNATS("nats://" + addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.close()
+	if err := c.publish("denied", []byte("1"), false); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.publish("allowed", []byte("2"), false); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "the allowed message", func() bool { return len(broker.received()) == 1 })
+	waitFor(t, "the PONG after the -ERR", func() bool {
+		broker.mu.Lock()
+		defer broker.mu.Unlock()
+		return broker.pongs > 0
+	})
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		t.Errorf("connection failed after a permissions violation: %v", c.err)
+	}
+}
+
+func TestNATSFatalErrors(t *testing.T) {
+	for line, fatal := range map[string]bool{
+		"-ERR 'Authorization Violation'":                      true,
+		"-ERR 'Stale Connection'":                             true,
+		"-ERR 'Maximum Payload Violation'":                    true,
+		"-ERR 'Invalid Subject'":                              false,
+		"-ERR 'Permissions Violation for Publish to \"a.b\"'": false,
+	} {
+		if got := natsFatalError(line); got != fatal {
+			t.Errorf("natsFatalError(%q) = %v, want %v", line, got, fatal)
+		}
+	}
+}
+
+func TestBusPublisherReconnects(t *testing.T) {
+	broker := &natsBroker{}
+	addr := testBroker(t, broker.serve)
+	p := newNATSPublisher(brokerConfig{URL: "nats://" + addr})
+	defer p.close()
+
+	p.send([]busMessage{{topic: "a", payload: []byte("1")}})
+	waitFor(t, "the first message", func() bool { return len(broker.received()) == 1 })
+
+	// Drop the connection and wait for the client to notice
+	broker.mu.Lock()
+	broker.conns[0].Close()
+	broker.mu.Unlock()
+	nc := p.conn.(*natsConn)
+	waitFor(t, "the dropped connection", func() bool {
+		nc.mu.Lock()
+		defer nc.mu.Unlock()
+		return nc.err != nil
+	})
+
+	// The failed message stays queued and goes out once reconnected
+	p.send([]busMessage{{topic: "a", payload: []byte("2")}})
+	if p.conn != nil || len(p.queue) != 1 {
+		t.Fatalf("after a failed publish: conn %v, %d queued", p.conn, len(p.queue))
+	}
+	p.send(nil)
+	waitFor(t, "the requeued message", func() bool { return len(broker.received()) == 2 })
+	if got := broker.received(); got[1] != "a 2" {
+		t.Errorf("received %q", got)
+	}
+}
+
+func TestBusPublisherBacksOff(t *testing.T) {
+	dials := 0
+	p := &busPublisher{
+		name:   "test",
+		escape: func(s string) string { return s },
+		dial: func() (busConn, error) {
+			dials++
+			return nil, errors.New("connection refused")
+		},
+	}
+
+	p.send([]busMessage{{topic: "a", payload: []byte("1")}})
+	if dials != 1 || p.backoff != time.Second {
+		t.Fatalf("after one failure: %d dials, backoff %s", dials, p.backoff)
+	}
+	// Messages are queued without redialling until the backoff has passed
+	p.send([]busMessage{{topic: "a", payload: []byte("2")}})
+	if dials != 1 || len(p.queue) != 2 {
+		t.Fatalf("during backoff: %d dials, %d queued", dials, len(p.queue))
+	}
+
+	for i := 0; i < 10; i++ {
+		p.retryAt = time.Time{}
+		p.send(nil)
+	}
+	if p.backoff != time.Minute {
+		t.Errorf("backoff %s, want it capped at a minute", p.backoff)
+	}
+
+	p.send(make([]busMessage, busQueueLimit))
+	if len(p.queue) != busQueueLimit || string(p.queue[0].payload) == "1" {
+		t.Errorf("queue holds %d messages; the oldest should be dropped", len(p.queue))
+	}
+}
+
+func TestBusTopics(t *testing.T) {
+	m := apiMatch{ID: "401", League: "eng.1", Home: apiSide{Name: "Brighton & Hove/Albion"}, Away: apiSide{Name: "Man #1 + Co"}}
+
+	mqtt := newMQTTPublisher(brokerConfig{})
+	if got := mqtt.topic(mqtt.eventTopic, m, eventGoal); got != "sharingan/eng.1/401/goal" {
+		t.Errorf("MQTT event topic %q", got)
+	}
+	if got := mqtt.topic("{home}/{away}", m, eventGoal); got != "Brighton & Hove_Albion/Man _1 _ Co" {
+		t.Errorf("MQTT escaping: %q", got)
+	}
+
+	nats := newNATSPublisher(brokerConfig{StateTopic: "scores.{league}.{home}"})
+	if got := nats.topic(nats.eventTopic, m, eventRedCard); got != "sharingan.eng_1.401.red_card" {
+		t.Errorf("NATS event topic %q", got)
+	}
+	if got := nats.topic(nats.stateTopic, m, "state"); got != "scores.eng_1.Brighton_&_Hove/Albion" {
+		t.Errorf("NATS state topic %q", got)
+	}
+	if got := nats.topic(nats.eventTopic, apiMatch{ID: "9"}, eventKickoff); got != "sharingan.all.9.kickoff" {
+		t.Errorf("topic without a league %q", got)
+	}
+}
@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var ratingsCmd = &cobra.Command{
+	Use:   "ratings",
+	Short: "Show Elo ratings for the teams of a league",
+	Long: `The 'ratings' command computes Elo ratings for every team in a league from
+its match results. Ratings include home advantage, scale with the goal
+difference and use a separate K-factor for league, cup, continental and
+friendly matches.
+
+Examples:
+  # Current Premier League ratings
+  sharingan ratings --league eng.1
+
+  # Ratings as they stood on a given date
+  sharingan ratings --league eng.1 --as-of 2025-01-01
+
+  # Also count FA Cup and Champions League results
+  sharingan ratings --league eng.1 --include eng.fa,uefa.champions
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return showRatings(cmd.Context())
+	},
+}
+
+// Flags specific to the ratings command
+var (
+	asOf           string
+	lastMatches    int
+	historyDays    int
+	includeLeagues []string
+)
+
+func init() {
+	rootCmd.AddCommand(ratingsCmd)
+
+	ratingsCmd.Flags().StringVarP(&league, "league", "l", "", "League slug (e.g. eng.1, esp.1)")
+	ratingsCmd.MarkFlagRequired("league")
+	ratingsCmd.Flags().StringVar(&asOf, "as-of", "", "Compute ratings as of this date (YYYY-MM-DD)")
+	ratingsCmd.Flags().IntVarP(&lastMatches, "last", "n", 5, "Show the rating change over the last N matches")
+	ratingsCmd.Flags().IntVar(&historyDays, "days", 365, "Number of days of results to rate")
+	ratingsCmd.Flags().StringSliceVar(&includeLeagues, "include", nil, "Other competitions whose results count (e.g. eng.fa,uefa.champions)")
+	ratingsCmd.Flags().StringVarP(&format, "format", "f", "pretty", "Output format (pretty, json)")
+}
+
+// parseAsOf returns the end of the --as-of day, or now when it is not set
+func parseAsOf(value string) (time.Time, error) {
+	if value == "" {
+		return time.Now(), nil
+	}
+	day, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, errInvalidInput("invalid date %q, expected YYYY-MM-DD", value)
+	}
+	return day.Add(24*time.Hour - time.Second), nil
+}
+
+// loadEloRatings rates the teams of a league and returns the table together
+// with the IDs of the teams that played in the league itself
+func loadEloRatings(ctx context.Context, leagueSlug string, include []string, until time.Time, days int) (*eloTable, map[string]bool, error) {
+	from := until.AddDate(0, 0, -days)
+
+	results, err := fetchLeagueResults(ctx, leagueSlug, from, until)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	members := make(map[string]bool)
+	for _, m := range results {
+		members[m.Home.ID] = true
+		members[m.Away.ID] = true
+	}
+
+	for _, other := range include {
+		extra, err := fetchLeagueResults(ctx, other, from, until)
+		if err != nil {
+			return nil, nil, err
+		}
+		results = append(results, extra...)
+	}
+
+	return buildEloTable(defaultEloConfig, results, until), members, nil
+}
+
+func showRatings(ctx context.Context) error {
+	until, err := parseAsOf(asOf)
+	if err != nil {
+		return err
+	}
+
+	table, members, err := loadEloRatings(ctx, league, includeLeagues, until, historyDays)
+	if err != nil {
+		return fmt.Errorf("fetching results: %w", err)
+	}
+
+	var ranked []*eloRating
+	for _, r := range table.ranked() {
+		if members[r.Team.ID] {
+			ranked = append(ranked, r)
+		}
+	}
+
+	if format == "json" {
+		out, _ := json.MarshalIndent(ranked, "", "  ")
+		fmt.Println(string(out))
+		return nil
+	}
+
+	if len(ranked) == 0 {
+		fmt.Println("No results found to rate.")
+		return nil
+	}
+
+	header := color.New(color.FgCyan, color.Bold).SprintFunc()
+	fmt.Printf("\n%s\n", header(fmt.Sprintf("ELO RATINGS: %s (as of %s)", strings.ToUpper(league), until.Format("2006-01-02"))))
+	fmt.Println("==================================================")
+	fmt.Printf("%-4s %-28s %7s %8s  %s\n", "#", "Team", "Rating", fmt.Sprintf("Last %d", lastMatches), "Trend")
+
+	for i, r := range ranked {
+		fmt.Printf("%-4d %-28s %7.0f %8s  %s\n", i+1, r.Team.DisplayName, r.Rating,
+			formatRatingChange(r.change(lastMatches)), trendLine(r, lastMatches))
+	}
+	return nil
+}
+
+// formatRatingChange colours a rating movement green or red
+func formatRatingChange(delta float64) string {
+	text := fmt.Sprintf("%+.0f", delta)
+	switch {
+	case delta >= 0.5:
+		return color.GreenString("%8s", text)
+	case delta <= -0.5:
+		return color.RedString("%8s", text)
+	default:
+		return fmt.Sprintf("%8s", text)
+	}
+}
+
+// trendLine renders a team's last n ratings as a sparkline
+func trendLine(r *eloRating, n int) string {
+	history := r.History
+	if len(history) > n {
+		history = history[len(history)-n:]
+	}
+	if len(history) == 0 {
+		return ""
+	}
+
+	ticks := []rune("▁▂▃▄▅▆▇█")
+	lo, hi := history[0].Rating, history[0].Rating
+	for _, p := range history {
+		lo = minFloat(lo, p.Rating)
+		hi = maxFloat(hi, p.Rating)
+	}
+
+	var sb strings.Builder
+	for _, p := range history {
+		idx := len(ticks) / 2
+		if hi > lo {
+			idx = int((p.Rating - lo) / (hi - lo) * float64(len(ticks)-1))
+		}
+		sb.WriteRune(ticks[idx])
+	}
+	return sb.String()
+}
+
+// displayTeamRating prints the Elo section of the team command
+func displayTeamRating(ctx context.Context, teamID, leagueSlug string) {
+	subtitleStyle := color.New(color.FgYellow).SprintFunc()
+	fmt.Printf("\n%s\n", subtitleStyle("ELO RATING"))
+
+	table, members, err := loadEloRatings(ctx, leagueSlug, nil, time.Now(), 365)
+	if err != nil {
+		fmt.Println("Error computing rating")
+		return
+	}
+
+	rating, ok := table.ratings[teamID]
+	if !ok {
+		fmt.Printf("No %s results found for this team\n", leagueSlug)
+		return
+	}
+
+	rank := 0
+	for _, r := range table.ranked() {
+		if members[r.Team.ID] {
+			rank++
+		}
+		if r.Team.ID == teamID {
+			break
+		}
+	}
+
+	fmt.Printf("Rating: %.0f (#%d in %s)\n", rating.Rating, rank, leagueSlug)
+	fmt.Printf("Last 10: %s %s\n", trendLine(rating, 10), strings.TrimSpace(formatRatingChange(rating.change(10))))
+}
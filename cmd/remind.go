@@ -0,0 +1,266 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// remindCmd represents the remind command
+var remindCmd = &cobra.Command{
+	Use:   "remind",
+	Short: "Send reminders before your favourite teams kick off",
+	Long: `The 'remind' command runs until interrupted, sending a reminder before each
+of your favourite teams' matches kicks off. Favourites are read from the
+config file, and fixtures are looked up again regularly so a rescheduled
+match gets its reminders at the new time.
+
+Reminders go through the same notifiers as 'notify' and include the venue
+and competition. Sent reminders are remembered between runs.
+
+Examples:
+  # Remind an hour and a quarter of an hour before kick-off
+  sharingan remind --before 60m,15m
+
+  # Send them to Discord
+  sharingan remind --before 30m --discord https://discord.com/api/webhooks/...
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runRemind(cmd.Context())
+	},
+}
+
+var (
+	remindBefore   []time.Duration
+	fixtureRefresh time.Duration
+)
+
+func init() {
+	rootCmd.AddCommand(remindCmd)
+
+	remindCmd.Flags().DurationSliceVar(&remindBefore, "before", []time.Duration{time.Hour, 15 * time.Minute}, "How long before kick-off to remind")
+	remindCmd.Flags().DurationVar(&fixtureRefresh, "refresh", 15*time.Minute, "How often to look up fixtures again")
+	remindCmd.Flags().StringSliceVarP(&notifyTeams, "team", "t", nil, "Teams to follow (default: favourites from the config)")
+	remindCmd.Flags().StringSliceVar(&webhookURLs, "webhook", nil, "URL to POST each reminder to as JSON")
+	remindCmd.Flags().StringSliceVar(&slackURLs, "slack", nil, "Slack incoming webhook URL")
+	remindCmd.Flags().StringSliceVar(&discordURLs, "discord", nil, "Discord webhook URL")
+	remindCmd.Flags().StringArrayVar(&notifyCommands, "exec", nil, "Shell command to run for each reminder")
+}
+
+// reminder is a notification due before a fixture
+type reminder struct {
+	event  Event
+	before time.Duration
+	due    time.Time
+}
+
+// key identifies a reminder. It includes the kick-off time, so rescheduling
+// a fixture gives its reminders new keys and they fire again at the new time.
+func (r reminder) key() string {
+	return fmt.Sprintf("%s:%s:%s", r.event.ID, r.before, r.event.Date)
+}
+
+// remindState records the reminders already sent, by key
+type remindState struct {
+	Sent map[string]time.Time `json:"sent"`
+}
+
+func runRemind(ctx context.Context) error {
+	if len(remindBefore) == 0 {
+		return errInvalidInput("--before needs at least one duration")
+	}
+	for _, before := range remindBefore {
+		if before <= 0 {
+			return errInvalidInput("--before durations must be positive, got %s", before)
+		}
+	}
+
+	path, err := statePath("remind-state.json")
+	if err != nil {
+		return err
+	}
+	state := remindState{Sent: make(map[string]time.Time)}
+	if data, err := os.ReadFile(path); err == nil {
+		if err := json.Unmarshal(data, &state); err != nil {
+			return fmt.Errorf("parsing %s: %w", path, err)
+		}
+	}
+	if state.Sent == nil {
+		state.Sent = make(map[string]time.Time)
+	}
+
+	notifiers := buildNotifiers()
+
+	var reminders []reminder
+	var lastRefresh time.Time
+	check := time.NewTicker(30 * time.Second)
+	defer check.Stop()
+
+	for {
+		if time.Since(lastRefresh) >= fixtureRefresh {
+			fresh, err := loadReminders(ctx)
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil // interrupted
+				}
+				slog.Error("looking up fixtures failed", "err", err)
+			} else {
+				reminders = fresh
+				lastRefresh = time.Now()
+				if len(reminders) > 0 {
+					next := reminders[0]
+					fmt.Printf("%d reminder(s) scheduled, next at %s for %s\n",
+						len(reminders), next.due.Local().Format("Mon 2 Jan 15:04"), next.event.Name)
+				}
+			}
+		}
+
+		if sendDueReminders(ctx, reminders, state.Sent, notifiers) {
+			if err := saveRemindState(path, state); err != nil {
+				slog.Warn("saving reminder state failed", "err", err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-check.C:
+		}
+	}
+}
+
+// loadReminders looks up the followed teams' fixtures and lists every
+// reminder that hasn't passed kick-off, soonest first
+func loadReminders(ctx context.Context) ([]reminder, error) {
+	teams := notifyTeams
+	if len(teams) == 0 {
+		cfg, err := loadConfig()
+		if err != nil {
+			return nil, err
+		}
+		teams = cfg.Favourites
+	}
+	if len(teams) == 0 {
+		return nil, errInvalidInput("no teams to follow: use --team or add favourites to the config")
+	}
+
+	now := time.Now()
+	seen := make(map[string]bool)
+	var reminders []reminder
+
+	for _, name := range teams {
+		found, ok, err := findTeam(ctx, strings.TrimSpace(name))
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			slog.Warn("team not found", "team", name)
+			continue
+		}
+
+		events, err := fetchTeamSchedule(ctx, found.ID, true)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, event := range events {
+			kickoff, err := parseEventTime(event.Date)
+			if err != nil || !kickoff.After(now) || seen[event.ID] {
+				continue
+			}
+			seen[event.ID] = true
+			for _, before := range remindBefore {
+				reminders = append(reminders, reminder{event: event, before: before, due: kickoff.Add(-before)})
+			}
+		}
+	}
+
+	sort.Slice(reminders, func(i, j int) bool { return reminders[i].due.Before(reminders[j].due) })
+	return reminders, nil
+}
+
+// sendDueReminders sends the reminders whose time has come and whose match
+// hasn't kicked off yet. When several reminders for a match are due at once
+// (after starting late, say) only the one closest to kick-off is sent. A
+// reminder that fails for some notifier stays pending, and is retried until
+// kick-off. It reports whether anything was sent.
+func sendDueReminders(ctx context.Context, reminders []reminder, sent map[string]time.Time, notifiers []notifier) bool {
+	now := time.Now()
+	changed := false
+
+	latest := make(map[string]reminder)
+	for _, r := range reminders {
+		if now.Before(r.due) || !now.Before(r.due.Add(r.before)) {
+			continue
+		}
+		if current, ok := latest[r.event.ID]; !ok || r.before < current.before {
+			latest[r.event.ID] = r
+		}
+	}
+
+	for _, r := range reminders {
+		if now.Before(r.due) || !now.Before(r.due.Add(r.before)) {
+			continue
+		}
+		if _, ok := sent[r.key()]; ok {
+			continue
+		}
+		if latest[r.event.ID].before != r.before {
+			sent[r.key()] = now
+			changed = true
+			continue
+		}
+
+		e := matchEvent{
+			ID:    r.key(),
+			Type:  eventReminder,
+			Match: normalizeMatch(r.event),
+			Time:  now.UTC(),
+		}
+
+		delivered := true
+		for _, n := range notifiers {
+			key := deliveryKey(e, n)
+			if _, ok := sent[key]; ok {
+				continue
+			}
+			if err := n.Notify(ctx, e); err != nil {
+				slog.Warn("reminder failed", "notifier", n.Name(), "event", r.event.Name, "err", err)
+				delivered = false
+				continue
+			}
+			sent[key] = now
+			changed = true
+		}
+		if delivered {
+			sent[r.key()] = now
+			changed = true
+		}
+	}
+	return changed
+}
+
+func saveRemindState(path string, state remindState) error {
+	for key, sent := range state.Sent {
+		if time.Since(sent) > sentRetention {
+			delete(state.Sent, key)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// matchResult is a completed match reduced to what the rating and
+// prediction models need
+type matchResult struct {
+	EventID   string    `json:"eventId"`
+	Date      time.Time `json:"date"`
+	League    string    `json:"league"`
+	Home      Team      `json:"home"`
+	Away      Team      `json:"away"`
+	HomeGoals int       `json:"homeGoals"`
+	AwayGoals int       `json:"awayGoals"`
+
+	// Fair-play points: 1 per yellow card, 3 per red card
+	HomeFairPlay int `json:"homeFairPlay"`
+	AwayFairPlay int `json:"awayFairPlay"`
+}
+
+// fetchLeagueEvents retrieves every event of a league between two dates.
+// The range is requested in monthly chunks so that busy leagues don't hit
+// the scoreboard's result limit.
+func fetchLeagueEvents(ctx context.Context, league string, from, to time.Time) ([]Event, error) {
+	var events []Event
+	seen := make(map[string]bool)
+
+	for start := from; !start.After(to); start = start.AddDate(0, 0, 31) {
+		end := start.AddDate(0, 0, 30)
+		if end.After(to) {
+			end = to
+		}
+
+		url := fmt.Sprintf("%s/%s/scoreboard?dates=%s-%s&limit=1000",
+			espnSoccerBase, league, start.Format("20060102"), end.Format("20060102"))
+
+		body, err := fetchESPN(ctx, url)
+		if err != nil {
+			return nil, err
+		}
+
+		var data ESPNResponse
+		if err := decodeESPN(league+" scoreboard", body, &data); err != nil {
+			return nil, err
+		}
+
+		for _, event := range data.Events {
+			if !seen[event.ID] {
+				seen[event.ID] = true
+				events = append(events, event)
+			}
+		}
+	}
+
+	return events, nil
+}
+
+// fetchCurrentSeason looks up the date range of a league's current season
+func fetchCurrentSeason(ctx context.Context, league string) (Season, time.Time, time.Time, error) {
+	body, err := fetchESPN(ctx, fmt.Sprintf("%s/%s/scoreboard", espnSoccerBase, league))
+	if err != nil {
+		return Season{}, time.Time{}, time.Time{}, err
+	}
+
+	var data ESPNResponse
+	if err := decodeESPN(league+" scoreboard", body, &data); err != nil {
+		return Season{}, time.Time{}, time.Time{}, err
+	}
+	if len(data.Leagues) == 0 {
+		return Season{}, time.Time{}, time.Time{}, errNotFound("league %s not found", league)
+	}
+
+	season := data.Leagues[0].Season
+	start, err := parseEventTime(season.StartDate)
+	if err != nil {
+		return season, time.Time{}, time.Time{}, &decodeError{"season start of " + league, err}
+	}
+	end, err := parseEventTime(season.EndDate)
+	if err != nil {
+		return season, time.Time{}, time.Time{}, &decodeError{"season end of " + league, err}
+	}
+	return season, start, end, nil
+}
+
+// fetchLeagueResults retrieves the completed matches of a league between two dates
+func fetchLeagueResults(ctx context.Context, league string, from, to time.Time) ([]matchResult, error) {
+	events, err := fetchLeagueEvents(ctx, league, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return completedResults(events, league), nil
+}
+
+// completedResults converts finished events into results sorted by kickoff.
+// Events without a parsable score or date are skipped, as are postponed and
+// abandoned matches, which are also "post" but not completed.
+func completedResults(events []Event, league string) []matchResult {
+	var results []matchResult
+	for _, event := range events {
+		if event.Status.Type.State != "post" || !event.Status.Type.Completed || len(event.Competitions) == 0 {
+			continue
+		}
+
+		home, away, ok := homeAndAway(event.Competitions[0])
+		if !ok {
+			continue
+		}
+
+		homeGoals, err := strconv.Atoi(string(home.Score))
+		if err != nil {
+			continue
+		}
+		awayGoals, err := strconv.Atoi(string(away.Score))
+		if err != nil {
+			continue
+		}
+
+		kickoff, err := parseEventTime(event.Date)
+		if err != nil {
+			continue
+		}
+
+		result := matchResult{
+			EventID:   event.ID,
+			Date:      kickoff,
+			League:    league,
+			Home:      home.Team,
+			Away:      away.Team,
+			HomeGoals: homeGoals,
+			AwayGoals: awayGoals,
+		}
+
+		for _, detail := range event.Competitions[0].Details {
+			points := 0
+			switch {
+			case detail.RedCard:
+				points = 3
+			case detail.YellowCard:
+				points = 1
+			}
+			switch detail.Team.ID {
+			case home.Team.ID:
+				result.HomeFairPlay += points
+			case away.Team.ID:
+				result.AwayFairPlay += points
+			}
+		}
+
+		results = append(results, result)
+	}
+
+	sortResults(results)
+	return results
+}
+
+// sortResults orders results by kickoff, falling back to the event ID so the
+// order is stable across runs
+func sortResults(results []matchResult) {
+	sort.SliceStable(results, func(i, j int) bool {
+		if !results[i].Date.Equal(results[j].Date) {
+			return results[i].Date.Before(results[j].Date)
+		}
+		return results[i].EventID < results[j].EventID
+	})
+}
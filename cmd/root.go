@@ -1,8 +1,13 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -11,33 +16,137 @@ import (
 var rootCmd = &cobra.Command{
 	Use:   "sharingan",
 	Short: "A CLI tool for fetching live scores, past matches, and team stats for different sports.",
-	Long:  `Sharingan is a CLI tool for retrieving real-time and past match data for football and other sports.`,
+	Long: `Sharingan is a CLI tool for retrieving real-time and past match data for football and other sports.
+
+Exit codes:
+  0    success (including "no matches found")
+  1    unexpected error
+  2    invalid input (bad flag, argument or date)
+  3    not found (unknown team, match or league)
+  4    network error (the API couldn't be reached)
+  5    the API answered with an HTTP error or an error payload
+  6    the API's response couldn't be parsed
+  7    the command ran past --timeout
+  130  interrupted (Ctrl-C)
+
+Diagnostics go to stderr, so stdout only carries the requested output.
+Warnings are shown by default; -v adds progress messages and -vv every
+upstream request. --log-format json writes them as JSON lines. To report
+a bug, add --har out.har and attach the file: it holds the last 1000
+requests and responses the command made, with timings. Credentials and
+webhook URLs are redacted.
+
+With --quiet, diagnostics are suppressed and a failure is reported on
+stderr as a single line:
+  error=<kind> code=<exit code> retryable=<true|false> message="<details>"
+
+retryable is true for network errors, timeouts, rate limiting and 5xx
+responses, where running the same command again later may succeed.`,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		commandStarted = true
+		if err := setupLogging(); err != nil {
+			return err
+		}
+		if harFile != "" {
+			harLog = &harRecorder{}
+		}
+		if commandTimeout > 0 {
+			var ctx context.Context
+			ctx, cancelTimeout = context.WithTimeout(cmd.Context(), commandTimeout)
+			commandCtx = ctx
+			cmd.SetContext(ctx)
+		}
+		// Cobra checks these after this hook; do it here so they count as
+		// invalid input
+		if err := cmd.ValidateRequiredFlags(); err != nil {
+			return &inputError{err.Error()}
+		}
+		if err := cmd.ValidateFlagGroups(); err != nil {
+			return &inputError{err.Error()}
+		}
+		return nil
+	},
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {
+		printFallbackBanner()
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 		fmt.Println("Use 'sharingan help' to see available commands")
 	},
 }
 
-// Execute runs the root command
+// Execute runs the root command and exits with the code for its error.
+// Ctrl-C or SIGTERM cancels the context every fetch runs under, and
+// long-running modes shut down cleanly.
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	err := rootCmd.ExecuteContext(ctx)
+	interrupted := ctx.Err() != nil
+	stop()
+	if harErr := writeHAR(); harErr != nil && err == nil {
+		err = harErr
+	}
+	if cancelTimeout != nil {
+		cancelTimeout()
 	}
+	if err == nil {
+		return
+	}
+	if commandCtx != nil && errors.Is(commandCtx.Err(), context.DeadlineExceeded) && !interrupted {
+		err = &timeoutError{After: commandTimeout, Err: err}
+	}
+	// Cobra rejects unknown commands, flags and bad arguments before the
+	// command runs
+	if !commandStarted {
+		err = &inputError{err.Error()}
+	}
+
+	kind, code := errorKind(err)
+	if quiet {
+		fmt.Fprintf(os.Stderr, "error=%s code=%d retryable=%t message=%q\n", kind, code, isRetryable(err), err.Error())
+	} else if kind == "interrupted" {
+		fmt.Fprintln(os.Stderr, "Interrupted")
+	} else {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	}
+	os.Exit(code)
 }
 
 // Common variables used across commands
 var (
-	league    string
-	date      string
-	team      string
-	dateRange int
-	detailed  bool
-	format    string
+	league     string
+	date       string
+	team       string
+	dateRange  int
+	detailed   bool
+	format     string
+	configFile string
+	quiet      bool
+
+	// commandStarted is set once cobra has validated the command line
+	commandStarted bool
+
+	// commandTimeout is the --timeout flag; commandCtx and cancelTimeout
+	// are the context it sets up
+	commandTimeout time.Duration
+	commandCtx     context.Context
+	cancelTimeout  context.CancelFunc
 )
 
 // Initialize commands
 func init() {
 	// Commands are added in their respective files
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", "", "Config file (default: <user config dir>/sharingan/config.json)")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Only print results; report failures as one line on stderr")
+	rootCmd.PersistentFlags().CountVarP(&verbosity, "verbose", "v", "Log progress to stderr (-vv to also log upstream requests)")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", "text", "Log format on stderr (text, json)")
+	rootCmd.PersistentFlags().IntVar(&maxRetries, "retries", 3, "Retries for failed upstream requests (network errors, 429 and 5xx)")
+	rootCmd.PersistentFlags().Float64Var(&requestsPerSec, "rps", 5, "Maximum upstream requests per second (0 for no limit)")
+	rootCmd.PersistentFlags().DurationVar(&commandTimeout, "timeout", 0, "Give up after this long, e.g. 30s (0 for no limit)")
+	rootCmd.PersistentFlags().BoolVar(&schemaCheck, "schema-check", false, "Warn when an ESPN payload doesn't match the expected model (see 'doctor schema')")
+	rootCmd.PersistentFlags().StringVar(&harFile, "har", "", "Record every HTTP request and response to this HAR file, e.g. for a bug report")
+	rootCmd.PersistentFlags().StringVar(&fallbackURL, "fallback", "", "ESPN-compatible mirror to use while ESPN is failing (overrides the config)")
 }
 
 // Helper functions
@@ -48,6 +157,20 @@ func min(a, b int) int {
 	return b
 }
 
+func minFloat(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxFloat(a, b float64) float64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
 func defaultIfEmpty(value, defaultValue string) string {
 	if value == "" {
 		return defaultValue
@@ -0,0 +1,491 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Alert rules are boolean expressions over a match event, e.g.
+//
+//	team == "Arsenal" && event == "goal_conceded"
+//	league == "eng.1" && total_goals >= 5
+//
+// Values are strings, numbers or booleans. Comparing strings ignores case,
+// and some variables carry several names at once (a team is its name,
+// abbreviation and ID), in which case == matches any of them.
+
+// ruleVariables documents the variables a rule can use
+var ruleVariables = map[string]string{
+	"event":           "event type: kickoff, goal, goal_scored, goal_conceded, red_card, red_card_received, opponent_red_card, half_time, full_time, status",
+	"team":            "the team the event is seen from (name, abbreviation or ID)",
+	"opponent":        "the other team",
+	"home":            "whether team is playing at home",
+	"league":          "league slug or name",
+	"team_goals":      "goals scored by team",
+	"opponent_goals":  "goals scored by the opponent",
+	"goal_difference": "team_goals - opponent_goals",
+	"total_goals":     "goals in the match",
+	"minute":          "match minute, 0 when unknown",
+	"state":           "pre, in or post",
+	"status":          "status detail, e.g. Halftime",
+}
+
+type ruleKind int
+
+const (
+	ruleString ruleKind = iota
+	ruleNumber
+	ruleBool
+)
+
+// ruleValue is the value of a variable or literal
+type ruleValue struct {
+	kind ruleKind
+	strs []string
+	num  float64
+	b    bool
+}
+
+func ruleStr(aliases ...string) ruleValue { return ruleValue{kind: ruleString, strs: aliases} }
+func ruleNum(n float64) ruleValue         { return ruleValue{kind: ruleNumber, num: n} }
+func ruleBoolean(b bool) ruleValue        { return ruleValue{kind: ruleBool, b: b} }
+
+func (k ruleKind) String() string {
+	return [...]string{"string", "number", "bool"}[k]
+}
+
+// ruleExpr is a node of a parsed rule. check works out the kind eval will
+// return from the kinds of the variables, so type errors are reported when
+// a rule is compiled rather than on every event.
+type ruleExpr interface {
+	eval(env map[string]ruleValue) (ruleValue, error)
+	check(vars map[string]ruleKind) (ruleKind, error)
+}
+
+type ruleLiteral struct{ value ruleValue }
+
+type ruleIdent struct{ name string }
+
+type ruleNot struct{ operand ruleExpr }
+
+type ruleBinary struct {
+	op          string
+	left, right ruleExpr
+}
+
+func (e ruleLiteral) eval(map[string]ruleValue) (ruleValue, error) { return e.value, nil }
+
+func (e ruleIdent) eval(env map[string]ruleValue) (ruleValue, error) {
+	v, ok := env[e.name]
+	if !ok {
+		return ruleValue{}, fmt.Errorf("unknown variable %q", e.name)
+	}
+	return v, nil
+}
+
+func (e ruleNot) eval(env map[string]ruleValue) (ruleValue, error) {
+	v, err := e.operand.eval(env)
+	if err != nil {
+		return v, err
+	}
+	if v.kind != ruleBool {
+		return v, fmt.Errorf("! needs a bool, got %s", v.kind)
+	}
+	return ruleBoolean(!v.b), nil
+}
+
+func (e ruleBinary) eval(env map[string]ruleValue) (ruleValue, error) {
+	left, err := e.left.eval(env)
+	if err != nil {
+		return left, err
+	}
+
+	// && and || short-circuit
+	if e.op == "&&" || e.op == "||" {
+		if left.kind != ruleBool {
+			return left, fmt.Errorf("%s needs bools, got %s", e.op, left.kind)
+		}
+		if left.b == (e.op == "||") {
+			return left, nil
+		}
+		right, err := e.right.eval(env)
+		if err != nil {
+			return right, err
+		}
+		if right.kind != ruleBool {
+			return right, fmt.Errorf("%s needs bools, got %s", e.op, right.kind)
+		}
+		return right, nil
+	}
+
+	right, err := e.right.eval(env)
+	if err != nil {
+		return right, err
+	}
+	if left.kind != right.kind {
+		return ruleValue{}, fmt.Errorf("cannot compare %s with %s", left.kind, right.kind)
+	}
+
+	switch e.op {
+	case "==":
+		return ruleBoolean(ruleEqual(left, right)), nil
+	case "!=":
+		return ruleBoolean(!ruleEqual(left, right)), nil
+	}
+
+	if left.kind != ruleNumber {
+		return ruleValue{}, fmt.Errorf("%s needs numbers, got %s", e.op, left.kind)
+	}
+	switch e.op {
+	case "<":
+		return ruleBoolean(left.num < right.num), nil
+	case "<=":
+		return ruleBoolean(left.num <= right.num), nil
+	case ">":
+		return ruleBoolean(left.num > right.num), nil
+	case ">=":
+		return ruleBoolean(left.num >= right.num), nil
+	}
+	return ruleValue{}, fmt.Errorf("unknown operator %s", e.op)
+}
+
+func (e ruleLiteral) check(map[string]ruleKind) (ruleKind, error) { return e.value.kind, nil }
+
+func (e ruleIdent) check(vars map[string]ruleKind) (ruleKind, error) {
+	kind, ok := vars[e.name]
+	if !ok {
+		return kind, fmt.Errorf("unknown variable %q", e.name)
+	}
+	return kind, nil
+}
+
+func (e ruleNot) check(vars map[string]ruleKind) (ruleKind, error) {
+	kind, err := e.operand.check(vars)
+	if err != nil {
+		return kind, err
+	}
+	if kind != ruleBool {
+		return kind, fmt.Errorf("! needs a bool, got %s", kind)
+	}
+	return ruleBool, nil
+}
+
+func (e ruleBinary) check(vars map[string]ruleKind) (ruleKind, error) {
+	left, err := e.left.check(vars)
+	if err != nil {
+		return left, err
+	}
+	right, err := e.right.check(vars)
+	if err != nil {
+		return right, err
+	}
+
+	switch e.op {
+	case "&&", "||":
+		if left != ruleBool || right != ruleBool {
+			return ruleBool, fmt.Errorf("%s needs bools, got %s and %s", e.op, left, right)
+		}
+		return ruleBool, nil
+	}
+	if left != right {
+		return ruleBool, fmt.Errorf("cannot compare %s with %s", left, right)
+	}
+	if e.op != "==" && e.op != "!=" && left != ruleNumber {
+		return ruleBool, fmt.Errorf("%s needs numbers, got %s", e.op, left)
+	}
+	return ruleBool, nil
+}
+
+// ruleVariableKinds returns the kind of every variable, taken from the
+// variables of an empty event
+func ruleVariableKinds() map[string]ruleKind {
+	kinds := make(map[string]ruleKind, len(ruleVariables))
+	for name, v := range ruleEnvs(matchEvent{})[0] {
+		kinds[name] = v.kind
+	}
+	return kinds
+}
+
+func ruleEqual(a, b ruleValue) bool {
+	switch a.kind {
+	case ruleNumber:
+		return a.num == b.num
+	case ruleBool:
+		return a.b == b.b
+	}
+	for _, x := range a.strs {
+		for _, y := range b.strs {
+			if strings.EqualFold(x, y) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ruleToken is a lexical token of a rule
+type ruleToken struct {
+	kind string // "ident", "string", "number", "op", "eof"
+	text string
+	pos  int
+}
+
+func lexRule(src string) ([]ruleToken, error) {
+	var tokens []ruleToken
+	for i := 0; i < len(src); {
+		c := rune(src[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '"':
+			j := i + 1
+			for j < len(src) && src[j] != '"' {
+				if src[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(src) {
+				return nil, fmt.Errorf("unterminated string at %d", i)
+			}
+			text, err := strconv.Unquote(src[i : j+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid string at %d: %w", i, err)
+			}
+			tokens = append(tokens, ruleToken{"string", text, i})
+			i = j + 1
+		case unicode.IsDigit(c) || c == '-' && i+1 < len(src) && unicode.IsDigit(rune(src[i+1])):
+			j := i + 1
+			for j < len(src) && (unicode.IsDigit(rune(src[j])) || src[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, ruleToken{"number", src[i:j], i})
+			i = j
+		case unicode.IsLetter(c) || c == '_':
+			j := i
+			for j < len(src) && (unicode.IsLetter(rune(src[j])) || unicode.IsDigit(rune(src[j])) || src[j] == '_') {
+				j++
+			}
+			tokens = append(tokens, ruleToken{"ident", src[i:j], i})
+			i = j
+		default:
+			op := ""
+			for _, candidate := range []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!", "(", ")"} {
+				if strings.HasPrefix(src[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected %q at %d", c, i)
+			}
+			tokens = append(tokens, ruleToken{"op", op, i})
+			i += len(op)
+		}
+	}
+	return append(tokens, ruleToken{"eof", "", len(src)}), nil
+}
+
+// ruleParser is a recursive descent parser for
+//
+//	or     = and { "||" and }
+//	and    = unary { "&&" unary }
+//	unary  = "!" unary | cmp
+//	cmp    = primary [ ("==" | "!=" | "<" | "<=" | ">" | ">=") primary ]
+//	primary = ident | string | number | "true" | "false" | "(" or ")"
+type ruleParser struct {
+	tokens []ruleToken
+	pos    int
+}
+
+// parseRule compiles a rule, checking that every variable exists and that
+// the rule is a well-typed condition
+func parseRule(src string) (ruleExpr, error) {
+	tokens, err := lexRule(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &ruleParser{tokens: tokens}
+	expr, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != "eof" {
+		return nil, fmt.Errorf("unexpected %q at %d", t.text, t.pos)
+	}
+	kind, err := expr.check(ruleVariableKinds())
+	if err != nil {
+		return nil, err
+	}
+	if kind != ruleBool {
+		return nil, fmt.Errorf("rule is a %s, not a condition", kind)
+	}
+	return expr, nil
+}
+
+func (p *ruleParser) peek() ruleToken { return p.tokens[p.pos] }
+
+func (p *ruleParser) next() ruleToken {
+	t := p.tokens[p.pos]
+	if t.kind != "eof" {
+		p.pos++
+	}
+	return t
+}
+
+func (p *ruleParser) or() (ruleExpr, error) {
+	left, err := p.and()
+	for err == nil && p.peek().text == "||" {
+		p.next()
+		var right ruleExpr
+		if right, err = p.and(); err == nil {
+			left = ruleBinary{"||", left, right}
+		}
+	}
+	return left, err
+}
+
+func (p *ruleParser) and() (ruleExpr, error) {
+	left, err := p.unary()
+	for err == nil && p.peek().text == "&&" {
+		p.next()
+		var right ruleExpr
+		if right, err = p.unary(); err == nil {
+			left = ruleBinary{"&&", left, right}
+		}
+	}
+	return left, err
+}
+
+func (p *ruleParser) unary() (ruleExpr, error) {
+	if t := p.peek(); t.kind == "op" && t.text == "!" {
+		p.next()
+		operand, err := p.unary()
+		return ruleNot{operand}, err
+	}
+	return p.cmp()
+}
+
+func (p *ruleParser) cmp() (ruleExpr, error) {
+	left, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	switch op := p.peek().text; op {
+	case "==", "!=", "<", "<=", ">", ">=":
+		p.next()
+		right, err := p.primary()
+		if err != nil {
+			return nil, err
+		}
+		return ruleBinary{op, left, right}, nil
+	}
+	return left, nil
+}
+
+func (p *ruleParser) primary() (ruleExpr, error) {
+	t := p.next()
+	switch t.kind {
+	case "string":
+		return ruleLiteral{ruleStr(t.text)}, nil
+	case "number":
+		n, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at %d", t.text, t.pos)
+		}
+		return ruleLiteral{ruleNum(n)}, nil
+	case "ident":
+		switch t.text {
+		case "true", "false":
+			return ruleLiteral{ruleBoolean(t.text == "true")}, nil
+		}
+		if _, ok := ruleVariables[t.text]; !ok {
+			return nil, fmt.Errorf("unknown variable %q at %d", t.text, t.pos)
+		}
+		return ruleIdent{t.text}, nil
+	case "op":
+		if t.text == "(" {
+			expr, err := p.or()
+			if err != nil {
+				return nil, err
+			}
+			if closing := p.next(); closing.text != ")" {
+				return nil, fmt.Errorf("expected ) at %d", closing.pos)
+			}
+			return expr, nil
+		}
+	case "eof":
+		return nil, fmt.Errorf("unexpected end of rule")
+	}
+	return nil, fmt.Errorf("unexpected %q at %d", t.text, t.pos)
+}
+
+// ruleEnvs returns the variables of an event seen from each team in the
+// match, so "team" and "opponent" rules work whichever side the team is on
+func ruleEnvs(e matchEvent) []map[string]ruleValue {
+	m := e.Match
+	minute := 0
+	if clock := strings.TrimRight(strings.SplitN(m.Clock, "'", 2)[0], " "); clock != "" {
+		if n, err := strconv.Atoi(strings.SplitN(clock, "+", 2)[0]); err == nil {
+			minute = n
+		}
+	}
+
+	var envs []map[string]ruleValue
+	for _, sides := range [][2]apiSide{{m.Home, m.Away}, {m.Away, m.Home}} {
+		team, opponent := sides[0], sides[1]
+		teamGoals, opponentGoals := scoreValue(team.Score), scoreValue(opponent.Score)
+
+		types := []string{e.Type}
+		switch e.Type {
+		case eventGoal:
+			if e.TeamID == team.ID {
+				types = append(types, "goal_scored")
+			} else {
+				types = append(types, "goal_conceded")
+			}
+		case eventRedCard:
+			if e.TeamID == team.ID {
+				types = append(types, "red_card_received")
+			} else {
+				types = append(types, "opponent_red_card")
+			}
+		}
+
+		envs = append(envs, map[string]ruleValue{
+			"event":           ruleStr(types...),
+			"team":            ruleStr(team.Name, team.Abbreviation, team.ID),
+			"opponent":        ruleStr(opponent.Name, opponent.Abbreviation, opponent.ID),
+			"home":            ruleBoolean(team.ID == m.Home.ID),
+			"league":          ruleStr(m.League, m.LeagueName),
+			"team_goals":      ruleNum(float64(teamGoals)),
+			"opponent_goals":  ruleNum(float64(opponentGoals)),
+			"goal_difference": ruleNum(float64(teamGoals - opponentGoals)),
+			"total_goals":     ruleNum(float64(teamGoals + opponentGoals)),
+			"minute":          ruleNum(float64(minute)),
+			"state":           ruleStr(m.State),
+			"status":          ruleStr(m.Status),
+		})
+	}
+	return envs
+}
+
+// ruleMatches reports whether a compiled rule holds for an event from the
+// point of view of either team
+func ruleMatches(expr ruleExpr, e matchEvent) (bool, error) {
+	for _, env := range ruleEnvs(e) {
+		v, err := expr.eval(env)
+		if err != nil {
+			return false, err
+		}
+		if v.kind != ruleBool {
+			return false, fmt.Errorf("rule is a %s, not a condition", v.kind)
+		}
+		if v.b {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,325 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// rulesCmd represents the rules command
+var rulesCmd = &cobra.Command{
+	Use:   "rules",
+	Short: "Run and test alert rules",
+	Long: `Alert rules live in the config file and send match events to notifiers
+when a condition holds, for example:
+
+  {
+    "notifiers": {
+      "phone": {"type": "exec", "command": "notify-send \"$SHARINGAN_EVENT_MESSAGE\""},
+      "team-chat": {"type": "slack", "url": "https://hooks.slack.com/services/..."}
+    },
+    "rules": [
+      {"name": "arsenal-concede", "when": "team == \"Arsenal\" && event == \"goal_conceded\"", "notify": ["phone"]},
+      {"name": "goal-fest", "when": "league == \"eng.1\" && event == \"goal\" && total_goals >= 5"}
+    ]
+  }
+
+A rule without "notify" uses every notifier. Conditions combine variables
+with ==, !=, <, <=, >, >=, &&, || and !, and are checked from the point of
+view of both teams. The variables are:
+
+` + ruleVariablesHelp(),
+}
+
+var rulesRunCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Evaluate alert rules against live matches",
+	Long: `The 'rules run' command polls today's matches and sends the events that
+match a rule to its notifiers. The config file is reloaded whenever it
+changes, so rules can be edited without a restart.
+
+Examples:
+  # Run the rules from the config file
+  sharingan rules run
+
+  # Also keep every scoreboard polled, for 'rules test --replay'
+  sharingan rules run --record ./snapshots
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runRules(cmd.Context())
+	},
+}
+
+var rulesTestCmd = &cobra.Command{
+	Use:   "test",
+	Short: "Check alert rules, optionally against recorded scoreboards",
+	Long: `The 'rules test' command checks that every rule in the config file
+compiles. With --replay it feeds a directory of recorded scoreboard
+responses (in file name order) through the event detector and shows which
+rules would have fired, without sending anything.
+
+Examples:
+  # Check the rules compile
+  sharingan rules test
+
+  # Replay a recorded matchday
+  sharingan rules test --replay ./snapshots
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return testRules(cmd.Context())
+	},
+}
+
+var (
+	replayDir string
+	recordDir string
+)
+
+func init() {
+	rootCmd.AddCommand(rulesCmd)
+	rulesCmd.AddCommand(rulesRunCmd, rulesTestCmd)
+
+	rulesRunCmd.Flags().DurationVar(&refreshInterval, "interval", 30*time.Second, "How often to poll the scoreboard")
+	rulesRunCmd.Flags().StringVar(&recordDir, "record", "", "Save every polled scoreboard to this directory")
+	rulesTestCmd.Flags().StringVar(&replayDir, "replay", "", "Directory of recorded scoreboard JSON files")
+}
+
+func ruleVariablesHelp() string {
+	names := make([]string, 0, len(ruleVariables))
+	for name := range ruleVariables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&b, "  %-16s %s\n", name, ruleVariables[name])
+	}
+	return b.String()
+}
+
+// compiledRule is an alert rule ready to evaluate
+type compiledRule struct {
+	alertRule
+	expr      ruleExpr
+	notifiers []notifier
+}
+
+// compileRules parses every rule in the config and resolves its notifiers
+func compileRules(cfg *config) ([]compiledRule, error) {
+	notifiers := make(map[string]notifier)
+	var all []notifier
+	names := make([]string, 0, len(cfg.Notifiers))
+	for name := range cfg.Notifiers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		n, err := cfg.Notifiers[name].build()
+		if err != nil {
+			return nil, fmt.Errorf("notifier %q: %w", name, err)
+		}
+		notifiers[name] = n
+		all = append(all, n)
+	}
+	if len(all) == 0 {
+		all = append(all, stdoutNotifier{})
+	}
+
+	var rules []compiledRule
+	for i, rule := range cfg.Rules {
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("rule %d", i+1)
+		}
+		expr, err := parseRule(rule.When)
+		if err != nil {
+			return nil, errInvalidInput("rule %q: %v", rule.Name, err)
+		}
+
+		compiled := compiledRule{alertRule: rule, expr: expr, notifiers: all}
+		if len(rule.Notify) > 0 {
+			compiled.notifiers = nil
+			for _, name := range rule.Notify {
+				n, ok := notifiers[name]
+				if !ok {
+					return nil, errInvalidInput("rule %q: unknown notifier %q", rule.Name, name)
+				}
+				compiled.notifiers = append(compiled.notifiers, n)
+			}
+		}
+		rules = append(rules, compiled)
+	}
+	return rules, nil
+}
+
+// matchingRules returns the rules an event triggers. Rules that fail to
+// evaluate are logged and skipped.
+func matchingRules(rules []compiledRule, e matchEvent) []compiledRule {
+	var matched []compiledRule
+	for _, rule := range rules {
+		ok, err := ruleMatches(rule.expr, e)
+		if err != nil {
+			slog.Warn("rule failed", "rule", rule.Name, "err", err)
+			continue
+		}
+		if ok {
+			matched = append(matched, rule)
+		}
+	}
+	return matched
+}
+
+// ruleSet holds the compiled rules and reloads them when the config file
+// changes
+type ruleSet struct {
+	path     string
+	modified time.Time
+	rules    []compiledRule
+}
+
+// reload recompiles the rules if the config file changed. A broken config
+// keeps the previous rules in place.
+func (s *ruleSet) reload() error {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return err
+	}
+	if info.ModTime().Equal(s.modified) {
+		return nil
+	}
+	s.modified = info.ModTime()
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	rules, err := compileRules(cfg)
+	if err != nil {
+		return err
+	}
+	s.rules = rules
+	fmt.Printf("Loaded %d rule(s) from %s\n", len(rules), s.path)
+	return nil
+}
+
+func runRules(ctx context.Context) error {
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+	set := &ruleSet{path: path}
+	if err := set.reload(); err != nil {
+		return err
+	}
+
+	if recordDir != "" {
+		if err := os.MkdirAll(recordDir, 0755); err != nil {
+			return err
+		}
+	}
+
+	detector := newEventDetector()
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := set.reload(); err != nil {
+			slog.Warn("keeping previous rules", "err", err)
+		}
+
+		events, err := fetchTodayEvents(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil // interrupted
+			}
+			slog.Error("polling scoreboard failed", "err", err)
+		} else {
+			if recordDir != "" {
+				recordSnapshot(events)
+			}
+			for _, e := range detector.update(events) {
+				for _, rule := range matchingRules(set.rules, e) {
+					for _, n := range rule.notifiers {
+						if err := n.Notify(ctx, e); err != nil {
+							slog.Warn("notification failed", "notifier", n.Name(), "rule", rule.Name, "err", err)
+						}
+					}
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// recordSnapshot saves a scoreboard so it can be replayed by 'rules test'
+func recordSnapshot(events []Event) {
+	data, err := json.Marshal(ESPNResponse{Events: events})
+	if err != nil {
+		return
+	}
+	name := filepath.Join(recordDir, time.Now().UTC().Format("20060102T150405Z")+".json")
+	if err := os.WriteFile(name, data, 0644); err != nil {
+		slog.Warn("recording scoreboard failed", "err", err)
+	}
+}
+
+func testRules(ctx context.Context) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	rules, err := compileRules(cfg)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%d rule(s) OK\n", len(rules))
+
+	if replayDir == "" {
+		return nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(replayDir, "*.json"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+	if len(files) == 0 {
+		return errInvalidInput("no .json files in %s", replayDir)
+	}
+
+	detector := newEventDetector()
+	fired := 0
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		var snapshot ESPNResponse
+		if err := json.Unmarshal(data, &snapshot); err != nil {
+			return fmt.Errorf("parsing %s: %w", file, err)
+		}
+
+		for _, e := range detector.update(snapshot.Events) {
+			for _, rule := range matchingRules(rules, e) {
+				fmt.Printf("%s  %-20s %s\n", filepath.Base(file), rule.Name, e)
+				fired++
+			}
+		}
+	}
+
+	fmt.Printf("\nReplayed %d snapshot(s): %d alert(s)\n", len(files), fired)
+	return nil
+}
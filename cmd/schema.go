@@ -0,0 +1,289 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ESPN changes the shape of its payloads without notice, and a field that
+// turns from a string into an object fails the whole decode. checkSchema
+// compares a payload with the model it is decoded into, for 'doctor schema'
+// and for --schema-check, which runs the comparison on every payload.
+
+// schemaCheck is the --schema-check flag
+var schemaCheck bool
+
+// schemaDrift is a difference between a payload and its model. Path names
+// the field, with [] for array elements and * for map values.
+type schemaDrift struct {
+	Path string `json:"path"`
+	// Kind is unknown (in the payload only), missing (in the model only) or
+	// type_changed
+	Kind     string `json:"kind"`
+	Expected string `json:"expected,omitempty"`
+	Actual   string `json:"actual,omitempty"`
+	// Count is how many times it occurs, e.g. once per event
+	Count int `json:"count"`
+}
+
+// checkSchema compares a JSON payload with the Go type it is decoded into.
+// Fields tagged omitempty are optional; fields of types that decode
+// themselves, such as Score, are checked by decoding them.
+func checkSchema(body []byte, model reflect.Type) ([]schemaDrift, error) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var payload interface{}
+	if err := dec.Decode(&payload); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[schemaDrift]int)
+	walkSchema("", payload, model, counts)
+
+	drift := make([]schemaDrift, 0, len(counts))
+	for d, n := range counts {
+		d.Count = n
+		drift = append(drift, d)
+	}
+	sort.Slice(drift, func(i, j int) bool {
+		if drift[i].Path != drift[j].Path {
+			return drift[i].Path < drift[j].Path
+		}
+		return drift[i].Kind < drift[j].Kind
+	})
+	return drift, nil
+}
+
+var unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+func walkSchema(path string, v interface{}, t reflect.Type, counts map[schemaDrift]int) {
+	if v == nil {
+		// null decodes into anything
+		return
+	}
+	changed := func() {
+		counts[schemaDrift{Path: path, Kind: "type_changed", Expected: schemaTypeName(t), Actual: jsonTypeName(v)}]++
+	}
+
+	if t.Kind() != reflect.Interface && reflect.PointerTo(t).Implements(unmarshalerType) {
+		raw, _ := json.Marshal(v)
+		if err := json.Unmarshal(raw, reflect.New(t).Interface()); err != nil {
+			changed()
+		}
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		walkSchema(path, v, t.Elem(), counts)
+	case reflect.Interface:
+		// Anything goes
+	case reflect.String:
+		if _, ok := v.(string); !ok {
+			changed()
+		}
+	case reflect.Bool:
+		if _, ok := v.(bool); !ok {
+			changed()
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, ok := v.(json.Number)
+		if !ok {
+			changed()
+		} else if _, err := n.Int64(); err != nil {
+			counts[schemaDrift{Path: path, Kind: "type_changed", Expected: "integer", Actual: "fractional number"}]++
+		}
+	case reflect.Float32, reflect.Float64:
+		if _, ok := v.(json.Number); !ok {
+			changed()
+		}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			if _, ok := v.(string); !ok {
+				changed()
+			}
+			return
+		}
+		items, ok := v.([]interface{})
+		if !ok {
+			changed()
+			return
+		}
+		for _, item := range items {
+			walkSchema(path+"[]", item, t.Elem(), counts)
+		}
+	case reflect.Map:
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			changed()
+			return
+		}
+		for _, value := range obj {
+			walkSchema(joinSchemaPath(path, "*"), value, t.Elem(), counts)
+		}
+	case reflect.Struct:
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			changed()
+			return
+		}
+		fields := schemaFields(t)
+		seen := make(map[string]bool, len(fields))
+		for key, value := range obj {
+			f, ok := matchSchemaField(fields, key)
+			if !ok {
+				counts[schemaDrift{Path: joinSchemaPath(path, key), Kind: "unknown", Actual: jsonTypeName(value)}]++
+				continue
+			}
+			seen[f.name] = true
+			walkSchema(joinSchemaPath(path, key), value, f.typ, counts)
+		}
+		for _, f := range fields {
+			if !f.optional && !seen[f.name] {
+				counts[schemaDrift{Path: joinSchemaPath(path, f.name), Kind: "missing", Expected: schemaTypeName(f.typ)}]++
+			}
+		}
+	}
+}
+
+// schemaField is a struct field as encoding/json sees it
+type schemaField struct {
+	name     string
+	typ      reflect.Type
+	optional bool
+}
+
+// schemaFields lists the JSON fields of a struct, including those of
+// embedded structs
+func schemaFields(t reflect.Type) []schemaField {
+	var fields []schemaField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			fields = append(fields, schemaFields(f.Type)...)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		fields = append(fields, schemaField{
+			name:     defaultIfEmpty(name, f.Name),
+			typ:      f.Type,
+			optional: strings.Contains(opts, "omitempty"),
+		})
+	}
+	return fields
+}
+
+// matchSchemaField finds the field a key decodes into, preferring an exact
+// match but ignoring case like encoding/json
+func matchSchemaField(fields []schemaField, key string) (schemaField, bool) {
+	for _, f := range fields {
+		if f.name == key {
+			return f, true
+		}
+	}
+	for _, f := range fields {
+		if strings.EqualFold(f.name, key) {
+			return f, true
+		}
+	}
+	return schemaField{}, false
+}
+
+func joinSchemaPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// schemaTypeName describes what a Go type expects in JSON terms
+func schemaTypeName(t reflect.Type) string {
+	if t.Kind() != reflect.Interface && reflect.PointerTo(t).Implements(unmarshalerType) {
+		return t.Name()
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return schemaTypeName(t.Elem())
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	}
+	return "any"
+}
+
+func jsonTypeName(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case json.Number:
+		return "number"
+	case bool:
+		return "boolean"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return "unknown"
+}
+
+// reportedDrift keeps --schema-check from repeating itself in poll loops
+var reportedDrift sync.Map
+
+// decodeESPN decodes an ESPN payload into v. With --schema-check it also
+// logs how the payload differs from v's type: fields that changed type or
+// went missing as warnings, new fields only with -v.
+func decodeESPN(what string, body []byte, v interface{}) error {
+	err := json.Unmarshal(body, v)
+	if schemaCheck {
+		logSchemaDrift(what, body, reflect.TypeOf(v).Elem())
+	}
+	if err != nil {
+		return &decodeError{what, err}
+	}
+	return nil
+}
+
+func logSchemaDrift(what string, body []byte, model reflect.Type) {
+	drift, err := checkSchema(body, model)
+	if err != nil {
+		return
+	}
+	for _, d := range drift {
+		if _, done := reportedDrift.LoadOrStore(what+"|"+d.Path+"|"+d.Kind, true); done {
+			continue
+		}
+		level := slog.LevelWarn
+		if d.Kind == "unknown" {
+			level = slog.LevelInfo
+		}
+		slog.Log(context.Background(), level, "payload doesn't match the model", "endpoint", what, "path", d.Path,
+			"drift", d.Kind, "expected", d.Expected, "actual", d.Actual)
+	}
+}
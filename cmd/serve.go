@@ -0,0 +1,435 @@
+package cmd
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve scores as a JSON HTTP API",
+	Long: `The 'serve' command runs an HTTP server exposing live scores, results,
+teams, standings and matches as normalized JSON. All clients share one
+upstream cache, so dashboards and bots don't each hit ESPN.
+
+Endpoints:
+  GET /v1/live?league=          Today's matches
+  GET /v1/past?date=&league=    Completed matches on a date (YYYY-MM-DD)
+  GET /v1/teams/{id}            Team details and next match
+  GET /v1/standings/{league}    League table (?computed=true to derive it)
+  GET /v1/matches/{id}?league=  A single match
+  GET /v1/stream?league=&team=  Match events as Server-Sent Events
+  GET /v1/ws?league=&team=      Match events over a WebSocket
+  GET /v1/openapi.json          OpenAPI description of the above
+  GET /metrics                  Prometheus metrics
+  GET /healthz                  Liveness probe
+  GET /readyz                   Readiness: has the provider answered recently?
+
+The scoreboard is polled once for all stream subscribers; goals, kick-offs
+and status changes are pushed to every client whose filters match.
+
+With --confirm-goals, a goal is only pushed to streams once a second source
+shows the same score; goals it never confirms are dropped. With "espn",
+goals in a match whose league is unknown can't be confirmed.
+
+While ESPN is failing, data comes from the fallback mirror or archived
+copies; API responses built from it carry the header
+"X-Sharingan-Source: fallback".
+
+Examples:
+  # Serve on the default address
+  sharingan serve
+
+  # Serve on another port with a longer cache
+  sharingan serve --addr :9090 --cache-ttl 1m
+
+  # Hold goals back until ESPN's per-league feeds confirm them
+  sharingan serve --confirm-goals espn
+
+  # Follow Arsenal's matches from a terminal
+  curl -N "localhost:8080/v1/stream?team=Arsenal"
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runServer(cmd.Context())
+	},
+}
+
+// Flags specific to the serve command
+var (
+	serveAddr    string
+	cacheTTL     time.Duration
+	pollInterval time.Duration
+	readyWindow  time.Duration
+	confirmGoals string
+	confirmWait  time.Duration
+)
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().StringVar(&serveAddr, "addr", ":8080", "Address to listen on")
+	serveCmd.Flags().DurationVar(&cacheTTL, "cache-ttl", 15*time.Second, "How long upstream responses are cached")
+	serveCmd.Flags().DurationVar(&pollInterval, "poll", 15*time.Second, "How often the scoreboard is polled for stream events")
+	serveCmd.Flags().DurationVar(&readyWindow, "ready-window", 2*time.Minute, "Report not ready when the provider hasn't answered for this long")
+	serveCmd.Flags().StringVar(&confirmGoals, "confirm-goals", "", `Hold goals until a second source agrees: "espn" for ESPN's per-league feeds, or a mirror URL`)
+	serveCmd.Flags().DurationVar(&confirmWait, "confirm-timeout", 5*time.Minute, "Drop goals the second source hasn't confirmed after this long")
+}
+
+//go:embed openapi.json
+var openAPIDocument []byte
+
+// apiServer serves the /v1 API from a provider
+type apiServer struct {
+	provider provider
+	hub      *eventHub
+	poll     *pollStats
+}
+
+// apiMatch is the normalized form of a match
+type apiMatch struct {
+	ID         string  `json:"id"`
+	League     string  `json:"league,omitempty"`
+	LeagueName string  `json:"leagueName,omitempty"`
+	Kickoff    string  `json:"kickoff"`
+	State      string  `json:"state"`
+	Status     string  `json:"status"`
+	StatusName string  `json:"statusName,omitempty"`
+	Clock      string  `json:"clock,omitempty"`
+	Venue      string  `json:"venue,omitempty"`
+	Home       apiSide `json:"home"`
+	Away       apiSide `json:"away"`
+}
+
+// apiSide is one team in a match
+type apiSide struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	Abbreviation string `json:"abbreviation,omitempty"`
+	Score        *int   `json:"score"`
+	RedCards     int    `json:"redCards,omitempty"`
+}
+
+// apiTeam is the normalized form of a team
+type apiTeam struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	Abbreviation string    `json:"abbreviation,omitempty"`
+	Logo         string    `json:"logo,omitempty"`
+	Standing     string    `json:"standing,omitempty"`
+	NextMatch    *apiMatch `json:"nextMatch,omitempty"`
+}
+
+// apiStandings is a league table
+type apiStandings struct {
+	League   string          `json:"league"`
+	Computed bool            `json:"computed"`
+	Rows     []*standingsRow `json:"rows"`
+}
+
+// apiError is the body of every error response
+type apiError struct {
+	Error string `json:"error"`
+}
+
+func runServer(ctx context.Context) error {
+	espn := newESPNProvider(cacheTTL)
+	api := newAPIServer(espn)
+
+	var confirmer *goalConfirmer
+	if confirmGoals != "" {
+		source, name, err := secondarySource(confirmGoals, espn, cacheTTL)
+		if err != nil {
+			return err
+		}
+		confirmer = &goalConfirmer{source: source, timeout: confirmWait, sameProvider: source == provider(espn)}
+		fmt.Printf("Goals are confirmed against %s\n", name)
+	}
+
+	ln, err := net.Listen("tcp", serveAddr)
+	if err != nil {
+		return fmt.Errorf("starting server: %w", err)
+	}
+	fmt.Printf("Serving on %s\n", serveAddr)
+	return api.serve(ctx, ln, confirmer)
+}
+
+// serve answers requests on ln and streams match events until ctx ends,
+// then shuts down gracefully: streams are closed and in-flight requests
+// get up to 10 seconds to finish
+func (s *apiServer) serve(ctx context.Context, ln net.Listener, confirmer *goalConfirmer) error {
+	server := &http.Server{
+		Handler:           s.routes(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	// Streams never go idle on their own, so end them before waiting on connections
+	server.RegisterOnShutdown(s.hub.close)
+
+	go pollEvents(ctx, s.provider, s.poll, s.hub, confirmer)
+
+	shutdown := make(chan struct{})
+	go func() {
+		defer close(shutdown)
+		<-ctx.Done()
+		fmt.Println("Shutting down...")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			slog.Error("shutdown failed", "err", err)
+		}
+	}()
+
+	if err := server.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("serving: %w", err)
+	}
+	// Serve returns as soon as shutdown starts; wait for it to finish
+	<-shutdown
+	return nil
+}
+
+func newAPIServer(p provider) *apiServer {
+	return &apiServer{provider: p, hub: newEventHub(), poll: &pollStats{interval: pollInterval}}
+}
+
+func (s *apiServer) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/live", withSourceHeader(s.handleLive))
+	mux.HandleFunc("GET /v1/past", withSourceHeader(s.handlePast))
+	mux.HandleFunc("GET /v1/teams/{id}", withSourceHeader(s.handleTeam))
+	mux.HandleFunc("GET /v1/standings/{league}", withSourceHeader(s.handleStandings))
+	mux.HandleFunc("GET /v1/matches/{id}", withSourceHeader(s.handleMatch))
+	mux.HandleFunc("GET /v1/stream", s.handleSSE)
+	mux.HandleFunc("GET /v1/ws", s.handleWebSocket)
+	mux.HandleFunc("GET /metrics", s.handleMetrics)
+	mux.HandleFunc("GET /healthz", s.handleHealthz)
+	mux.HandleFunc("GET /readyz", s.handleReadyz)
+	mux.HandleFunc("GET /v1/openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(openAPIDocument)
+	})
+
+	return mux
+}
+
+// withSourceHeader tags responses built from fallback data with
+// "X-Sharingan-Source: fallback"
+func withSourceHeader(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		src := &fetchSource{}
+		handler(&sourceWriter{ResponseWriter: w, src: src}, r.WithContext(withFetchSource(r.Context(), src)))
+	}
+}
+
+// sourceWriter sets the source header just before the response starts,
+// once the handler has fetched what it needs
+type sourceWriter struct {
+	http.ResponseWriter
+	src     *fetchSource
+	written bool
+}
+
+func (w *sourceWriter) WriteHeader(status int) {
+	if !w.written {
+		w.written = true
+		if w.src.fallback.Load() {
+			w.Header().Set("X-Sharingan-Source", "fallback")
+		}
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *sourceWriter) Write(b []byte) (int, error) {
+	if !w.written {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (s *apiServer) handleLive(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	events, err := s.provider.Scoreboard(ctx, "all", "")
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+
+	filter := r.URL.Query().Get("league")
+	matches := []apiMatch{}
+	for _, event := range events {
+		if eventMatchesLeague(event, filter) {
+			matches = append(matches, normalizeMatch(event))
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"matches": matches})
+}
+
+func (s *apiServer) handlePast(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	day := time.Now().AddDate(0, 0, -1)
+	if value := r.URL.Query().Get("date"); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", value))
+			return
+		}
+		day = parsed
+	}
+
+	events, err := s.provider.Scoreboard(ctx, "all", day.Format("20060102"))
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+
+	filter := r.URL.Query().Get("league")
+	matches := []apiMatch{}
+	for _, event := range events {
+		if event.Status.Type.State == "post" && eventMatchesLeague(event, filter) {
+			matches = append(matches, normalizeMatch(event))
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"date": day.Format("2006-01-02"), "matches": matches})
+}
+
+func (s *apiServer) handleTeam(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	detail, err := s.provider.Team(ctx, r.PathValue("id"))
+	if err != nil {
+		writeError(w, upstreamStatus(err), err)
+		return
+	}
+
+	t := detail.Team
+	result := apiTeam{
+		ID:           t.ID,
+		Name:         t.DisplayName,
+		Abbreviation: t.Abbreviation,
+		Logo:         t.Logo,
+		Standing:     t.StandingSummary,
+	}
+	if len(t.NextEvent) > 0 {
+		next := normalizeMatch(t.NextEvent[0])
+		result.NextMatch = &next
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *apiServer) handleStandings(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	leagueSlug := r.PathValue("league")
+	result := apiStandings{League: leagueSlug, Computed: r.URL.Query().Get("computed") == "true"}
+
+	var err error
+	if result.Computed {
+		result.Rows, err = s.provider.ComputedStandings(ctx, leagueSlug)
+	} else {
+		result.Rows, err = s.provider.Standings(ctx, leagueSlug)
+	}
+	if err != nil {
+		writeError(w, upstreamStatus(err), err)
+		return
+	}
+	if len(result.Rows) == 0 {
+		writeError(w, http.StatusNotFound, fmt.Errorf("no standings for %s", leagueSlug))
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *apiServer) handleMatch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	event, err := s.provider.Match(ctx, r.URL.Query().Get("league"), r.PathValue("id"))
+	if err != nil {
+		writeError(w, upstreamStatus(err), err)
+		return
+	}
+	writeJSON(w, http.StatusOK, normalizeMatch(event))
+}
+
+// normalizeMatch converts an ESPN event into the API's match shape
+func normalizeMatch(event Event) apiMatch {
+	m := apiMatch{
+		ID:         event.ID,
+		League:     event.League.Slug,
+		LeagueName: event.League.Name,
+		Kickoff:    event.Date,
+		State:      event.Status.Type.State,
+		Status:     event.Status.Type.Detail,
+		StatusName: event.Status.Type.Name,
+	}
+	if kickoff, err := parseEventTime(event.Date); err == nil {
+		m.Kickoff = kickoff.UTC().Format(time.RFC3339)
+	}
+	if len(event.Competitions) == 0 {
+		return m
+	}
+
+	c := event.Competitions[0]
+	m.Venue = c.Venue.FullName
+	if m.State == "" {
+		m.State, m.Status, m.StatusName = c.Status.Type.State, c.Status.Type.Detail, c.Status.Type.Name
+	}
+	if m.State == "in" {
+		m.Clock = defaultIfEmpty(event.Status.DisplayClock, c.Status.DisplayClock)
+	}
+
+	if home, away, ok := homeAndAway(c); ok {
+		m.Home, m.Away = normalizeSide(home, m.State), normalizeSide(away, m.State)
+		for _, d := range c.Details {
+			if !d.RedCard {
+				continue
+			}
+			switch d.Team.ID {
+			case m.Home.ID:
+				m.Home.RedCards++
+			case m.Away.ID:
+				m.Away.RedCards++
+			}
+		}
+	}
+	return m
+}
+
+func normalizeSide(c Competitor, state string) apiSide {
+	side := apiSide{ID: c.Team.ID, Name: c.Team.DisplayName, Abbreviation: c.Team.Abbreviation}
+	if state != "pre" {
+		if score, err := strconv.Atoi(string(c.Score)); err == nil {
+			side.Score = &score
+		}
+	}
+	return side
+}
+
+// upstreamStatus maps a provider error to the HTTP status returned to clients
+func upstreamStatus(err error) int {
+	var upstream *httpError
+	if errors.As(err, &upstream) && upstream.StatusCode == http.StatusNotFound {
+		return http.StatusNotFound
+	}
+	switch _, code := errorKind(err); code {
+	case exitNotFound:
+		return http.StatusNotFound
+	case exitInvalidInput:
+		return http.StatusBadRequest
+	}
+	return http.StatusBadGateway
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, apiError{Error: err.Error()})
+}
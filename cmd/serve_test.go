@@ -0,0 +1,478 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// stubProvider is a provider that answers from memory
+type stubProvider struct {
+	events    []Event
+	teams     map[string]teamDetail
+	standings []*standingsRow
+
+	scoreboards atomic.Int32
+}
+
+func (p *stubProvider) Name() string { return "stub" }
+
+func (p *stubProvider) Scoreboard(ctx context.Context, league, date string) ([]Event, error) {
+	p.scoreboards.Add(1)
+	return p.events, nil
+}
+
+func (p *stubProvider) Team(ctx context.Context, id string) (teamDetail, error) {
+	t, ok := p.teams[id]
+	if !ok {
+		return teamDetail{}, errNotFound("team %s not found", id)
+	}
+	return t, nil
+}
+
+func (p *stubProvider) Standings(ctx context.Context, league string) ([]*standingsRow, error) {
+	return p.standings, nil
+}
+
+func (p *stubProvider) ComputedStandings(ctx context.Context, league string) ([]*standingsRow, error) {
+	return p.standings, nil
+}
+
+func (p *stubProvider) Match(ctx context.Context, league, id string) (Event, error) {
+	for _, e := range p.events {
+		if e.ID == id {
+			return e, nil
+		}
+	}
+	return Event{}, errNotFound("match %s not found", id)
+}
+
+func testEvent() Event {
+	return Event{
+		ID:     "401",
+		Date:   "2025-03-15T15:00Z",
+		Status: Status{Type: StatusType{State: "in", Detail: "55'"}},
+		League: League{Slug: "eng.1", Name: "Premier League", Abbreviation: "EPL"},
+		Competitions: []Competition{{Competitors: []Competitor{
+			{HomeAway: "home", Score: "1", Team: Team{ID: "359", DisplayName: "Arsenal"}},
+			{HomeAway: "away", Score: "0", Team: Team{ID: "363", DisplayName: "Chelsea"}},
+		}}},
+	}
+}
+
+func testMatchEvent() matchEvent {
+	return matchEvent{ID: "401-goal-1-0", Type: eventGoal, Match: normalizeMatch(testEvent()), TeamID: "359", Time: time.Now()}
+}
+
+func newTestAPI(p provider) *apiServer {
+	api := newAPIServer(p)
+	api.poll.interval = time.Hour
+	return api
+}
+
+func getJSON(t *testing.T, url string, v interface{}) int {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatalf("decoding %s: %v", url, err)
+	}
+	return resp.StatusCode
+}
+
+// waitFor polls cond until it holds or a second has passed
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestHandlers(t *testing.T) {
+	p := &stubProvider{
+		events:    []Event{testEvent()},
+		teams:     map[string]teamDetail{},
+		standings: []*standingsRow{{Position: 1, Team: Team{ID: "359", DisplayName: "Arsenal"}, Points: 70}},
+	}
+	srv := httptest.NewServer(newTestAPI(p).routes())
+	defer srv.Close()
+
+	var live struct{ Matches []apiMatch }
+	if code := getJSON(t, srv.URL+"/v1/live?league=eng.1", &live); code != http.StatusOK {
+		t.Fatalf("live: status %d", code)
+	}
+	if len(live.Matches) != 1 || live.Matches[0].Home.Name != "Arsenal" || *live.Matches[0].Home.Score != 1 {
+		t.Errorf("live: unexpected matches %+v", live.Matches)
+	}
+
+	if getJSON(t, srv.URL+"/v1/live?league=esp.1", &live); len(live.Matches) != 0 {
+		t.Errorf("live: league filter kept %d matches", len(live.Matches))
+	}
+
+	var apiErr apiError
+	if code := getJSON(t, srv.URL+"/v1/teams/1", &apiErr); code != http.StatusNotFound {
+		t.Errorf("unknown team: status %d, want 404", code)
+	}
+	if code := getJSON(t, srv.URL+"/v1/past?date=yesterday", &apiErr); code != http.StatusBadRequest {
+		t.Errorf("bad date: status %d, want 400", code)
+	}
+
+	var table apiStandings
+	if code := getJSON(t, srv.URL+"/v1/standings/eng.1?computed=true", &table); code != http.StatusOK {
+		t.Fatalf("standings: status %d", code)
+	}
+	if !table.Computed || len(table.Rows) != 1 || table.Rows[0].Points != 70 {
+		t.Errorf("standings: unexpected table %+v", table)
+	}
+}
+
+func TestServeCachesUpstreamResponses(t *testing.T) {
+	body, _ := json.Marshal(ESPNResponse{Events: []Event{testEvent()}})
+	var fetches atomic.Int32
+	p := &espnProvider{
+		name: "fake",
+		base: "http://upstream.test",
+		fetch: func(ctx context.Context, url string) ([]byte, error) {
+			fetches.Add(1)
+			return body, nil
+		},
+		cache: newResponseCache(time.Minute),
+	}
+	srv := httptest.NewServer(newTestAPI(p).routes())
+	defer srv.Close()
+
+	for i := 0; i < 3; i++ {
+		var live struct{ Matches []apiMatch }
+		if code := getJSON(t, srv.URL+"/v1/live", &live); code != http.StatusOK || len(live.Matches) != 1 {
+			t.Fatalf("live: status %d, %d matches", code, len(live.Matches))
+		}
+	}
+	if n := fetches.Load(); n != 1 {
+		t.Errorf("upstream fetched %d times, want 1", n)
+	}
+}
+
+func TestServeTagsFallbackResponses(t *testing.T) {
+	body, _ := json.Marshal(ESPNResponse{Events: []Event{testEvent()}})
+	p := &espnProvider{
+		name: "fake",
+		base: "http://upstream.test",
+		fetch: func(ctx context.Context, url string) ([]byte, error) {
+			if strings.Contains(url, "dates=") {
+				markFallback(ctx)
+			}
+			return body, nil
+		},
+		cache: newResponseCache(time.Minute),
+	}
+	srv := httptest.NewServer(newTestAPI(p).routes())
+	defer srv.Close()
+
+	source := func(path string) string {
+		resp, err := http.Get(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.Header.Get("X-Sharingan-Source")
+	}
+	// Twice each, so the second answer comes from the cache
+	for i := 0; i < 2; i++ {
+		if got := source("/v1/past?date=2024-03-20"); got != "fallback" {
+			t.Errorf("past from a fallback: source %q", got)
+		}
+		if got := source("/v1/live"); got != "" {
+			t.Errorf("live from ESPN: source %q", got)
+		}
+	}
+	if got := source("/healthz"); got != "" {
+		t.Errorf("healthz: source %q", got)
+	}
+}
+
+func TestResponseCacheSharesFetches(t *testing.T) {
+	release := make(chan struct{})
+	var fetches atomic.Int32
+	fetch := func(ctx context.Context, url string) ([]byte, error) {
+		fetches.Add(1)
+		<-release
+		return []byte(url), nil
+	}
+	c := newResponseCache(time.Minute)
+
+	var wg sync.WaitGroup
+	results := make([]string, 5)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			body, err := c.get(context.Background(), "a", fetch)
+			if err != nil {
+				t.Error(err)
+			}
+			results[i] = string(body)
+		}(i)
+	}
+	waitFor(t, "the shared fetch", func() bool { return fetches.Load() == 1 })
+	time.Sleep(20 * time.Millisecond) // let the other callers join it
+	close(release)
+	wg.Wait()
+
+	if n := fetches.Load(); n != 1 {
+		t.Errorf("fetched %d times, want 1", n)
+	}
+	for _, r := range results {
+		if r != "a" {
+			t.Errorf("got %q, want %q", r, "a")
+		}
+	}
+}
+
+func TestResponseCacheSurvivesFirstCallerLeaving(t *testing.T) {
+	release := make(chan struct{})
+	fetch := func(ctx context.Context, url string) ([]byte, error) {
+		select {
+		case <-release:
+			return []byte("body"), nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	c := newResponseCache(time.Minute)
+
+	first, cancel := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := c.get(first, "a", fetch)
+		firstErr <- err
+	}()
+	waitFor(t, "the fetch to start", func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return c.inflight["a"] != nil
+	})
+
+	second := make(chan string, 1)
+	go func() {
+		body, err := c.get(context.Background(), "a", fetch)
+		if err != nil {
+			t.Error(err)
+		}
+		second <- string(body)
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	cancel()
+	if err := <-firstErr; !errors.Is(err, context.Canceled) {
+		t.Errorf("first caller: got %v, want context.Canceled", err)
+	}
+	close(release)
+	if body := <-second; body != "body" {
+		t.Errorf("second caller: got %q", body)
+	}
+}
+
+func TestResponseCacheEvictsExpiredEntries(t *testing.T) {
+	c := newResponseCache(10 * time.Millisecond)
+	fetch := func(ctx context.Context, url string) ([]byte, error) { return []byte(url), nil }
+
+	for i := 0; i < 50; i++ {
+		c.get(context.Background(), fmt.Sprint("old-", i), fetch)
+	}
+	time.Sleep(20 * time.Millisecond)
+	c.get(context.Background(), "new", fetch)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) != 1 {
+		t.Errorf("%d entries left, want 1", len(c.entries))
+	}
+}
+
+func TestSSEStream(t *testing.T) {
+	api := newTestAPI(&stubProvider{})
+	srv := httptest.NewServer(api.routes())
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/v1/stream?team=Arsenal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type %q", ct)
+	}
+	waitFor(t, "the subscriber", func() bool { return api.hub.count() == 1 })
+
+	other := testMatchEvent()
+	other.ID, other.Match.Home.Name = "ignored", "Everton"
+	api.hub.publish([]matchEvent{other, testMatchEvent()})
+
+	reader := bufio.NewReader(resp.Body)
+	var lines []string
+	for len(lines) < 3 {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "retry:") {
+			lines = append(lines, line)
+		}
+	}
+	if lines[0] != "id: 401-goal-1-0" || lines[1] != "event: goal" {
+		t.Errorf("unexpected event header %q", lines[:2])
+	}
+	var e matchEvent
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(lines[2], "data: ")), &e); err != nil || e.Match.Home.Name != "Arsenal" {
+		t.Errorf("unexpected data %q (%v)", lines[2], err)
+	}
+}
+
+func TestWebSocketStream(t *testing.T) {
+	api := newTestAPI(&stubProvider{})
+	srv := httptest.NewServer(api.routes())
+	defer srv.Close()
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(srv.URL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	fmt.Fprint(conn, "GET /v1/ws HTTP/1.1\r\nHost: test\r\nConnection: Upgrade\r\nUpgrade: websocket\r\n"+
+		"Sec-WebSocket-Version: 13\r\nSec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n\r\n")
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("handshake: status %d", resp.StatusCode)
+	}
+	// The accept value for this key is given in RFC 6455 section 1.3
+	if accept := resp.Header.Get("Sec-WebSocket-Accept"); accept != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("handshake: Sec-WebSocket-Accept %q", accept)
+	}
+
+	waitFor(t, "the subscriber", func() bool { return api.hub.count() == 1 })
+	api.hub.publish([]matchEvent{testMatchEvent()})
+
+	opcode, payload := readTestFrame(t, reader)
+	if opcode != wsText {
+		t.Fatalf("opcode %#x, want text", opcode)
+	}
+	var e matchEvent
+	if err := json.Unmarshal(payload, &e); err != nil || e.Type != eventGoal {
+		t.Errorf("unexpected message %q (%v)", payload, err)
+	}
+}
+
+// readTestFrame reads an unmasked frame sent by the server
+func readTestFrame(t *testing.T, r io.Reader) (byte, []byte) {
+	t.Helper()
+	var head [2]byte
+	if _, err := io.ReadFull(r, head[:]); err != nil {
+		t.Fatal(err)
+	}
+	length := uint64(head[1] & 0x7F)
+	switch length {
+	case 126:
+		var ext [2]byte
+		io.ReadFull(r, ext[:])
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		io.ReadFull(r, ext[:])
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		t.Fatal(err)
+	}
+	return head[0] & 0x0F, payload
+}
+
+func TestServeShutsDownGracefully(t *testing.T) {
+	p := &stubProvider{events: []Event{testEvent()}}
+	api := newTestAPI(p)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- api.serve(ctx, ln, nil) }()
+
+	url := "http://" + ln.Addr().String()
+	var live struct{ Matches []apiMatch }
+	if code := getJSON(t, url+"/v1/live", &live); code != http.StatusOK {
+		t.Fatalf("live: status %d", code)
+	}
+	waitFor(t, "the first poll", func() bool { return api.poll.lastPoll.Load() != 0 })
+
+	stream, err := http.Get(url + "/v1/stream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Body.Close()
+	waitFor(t, "the subscriber", func() bool { return api.hub.count() == 1 })
+
+	cancel()
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Fatalf("serve: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("serve didn't return after cancellation")
+	}
+
+	// The open stream is ended rather than left hanging
+	if _, err := io.ReadAll(stream.Body); err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("reading the stream after shutdown: %v", err)
+	}
+	if _, err := http.Get(url + "/healthz"); err == nil {
+		t.Error("server still accepting requests after shutdown")
+	}
+}
+
+func TestGoalConfirmerNeedsALeagueFromTheSameProvider(t *testing.T) {
+	goal := testMatchEvent()
+	goal.Match.League = ""
+	current := map[string]apiMatch{goal.Match.ID: goal.Match}
+	p := &stubProvider{events: []Event{testEvent()}}
+
+	same := &goalConfirmer{source: p, timeout: time.Minute, sameProvider: true}
+	if ready := same.filter(context.Background(), []matchEvent{goal}, current); len(ready) != 0 || len(same.pending) != 1 {
+		t.Errorf("same provider: %d ready, %d pending; want the goal held", len(ready), len(same.pending))
+	}
+	if n := p.scoreboards.Load(); n != 0 {
+		t.Errorf("same provider: %d scoreboard fetches, want none", n)
+	}
+
+	other := &goalConfirmer{source: p, timeout: time.Minute}
+	if ready := other.filter(context.Background(), []matchEvent{goal}, current); len(ready) != 1 {
+		t.Errorf("independent source: %d ready, want the goal confirmed", len(ready))
+	}
+}
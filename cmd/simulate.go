@@ -0,0 +1,314 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var simulateCmd = &cobra.Command{
+	Use:   "simulate",
+	Short: "Simulate the rest of a league season",
+	Long: `The 'simulate' command plays out the remaining fixtures of a league season
+thousands of times using the Poisson goal model, starting from the current
+table. It reports the probability of every final position together with
+title, top-4 and relegation odds. Teams level on points in a simulated
+table are separated by the league's tiebreak rules, head-to-head included,
+as in 'standings --computed'.
+
+Examples:
+  # Simulate the rest of the Premier League season
+  sharingan simulate --league eng.1
+
+  # Reproducible output with a fixed seed
+  sharingan simulate --league eng.1 --runs 10000 --seed 42
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !cmd.Flags().Changed("seed") {
+			simSeed = time.Now().UnixNano()
+		}
+		return runSimulation(cmd.Context())
+	},
+}
+
+// Flags specific to the simulate command
+var (
+	simRuns       int
+	simSeed       int64
+	simRelegation int
+)
+
+func init() {
+	rootCmd.AddCommand(simulateCmd)
+
+	simulateCmd.Flags().StringVarP(&league, "league", "l", "", "League slug (e.g. eng.1, esp.1)")
+	simulateCmd.MarkFlagRequired("league")
+	simulateCmd.Flags().IntVar(&simRuns, "runs", 10000, "Number of simulated seasons")
+	simulateCmd.Flags().Int64Var(&simSeed, "seed", 0, "Random seed for reproducible output")
+	simulateCmd.Flags().IntVar(&simRelegation, "relegation", 3, "Number of relegation places")
+	simulateCmd.Flags().StringVarP(&format, "format", "f", "pretty", "Output format (pretty, json)")
+}
+
+// seasonOdds is the simulated outcome of one team's season
+type seasonOdds struct {
+	Team           Team      `json:"team"`
+	Points         int       `json:"points"`
+	ExpectedPoints float64   `json:"expectedPoints"`
+	Positions      []float64 `json:"positions"`
+	Title          float64   `json:"title"`
+	Top4           float64   `json:"top4"`
+	Relegation     float64   `json:"relegation"`
+}
+
+// simFixture is a remaining fixture with its precomputed goal expectations
+type simFixture struct {
+	home, away             int
+	lambdaHome, lambdaAway float64
+}
+
+func runSimulation(ctx context.Context) error {
+	if simRuns <= 0 {
+		return errInvalidInput("--runs must be at least 1")
+	}
+	season, start, end, err := fetchCurrentSeason(ctx, league)
+	if err != nil {
+		return fmt.Errorf("fetching season: %w", err)
+	}
+
+	events, err := fetchLeagueEvents(ctx, league, start, end)
+	if err != nil {
+		return fmt.Errorf("fetching fixtures: %w", err)
+	}
+
+	// The strength model also looks at last season so early-season runs
+	// aren't driven by a handful of results
+	now := time.Now()
+	history, err := fetchLeagueResults(ctx, league, now.AddDate(0, 0, -365), now)
+	if err != nil {
+		return fmt.Errorf("fetching results: %w", err)
+	}
+	model := fitPoissonModel(history, now)
+
+	rules, err := tiebreakRulesFor(league, nil)
+	if err != nil {
+		return err
+	}
+	results := completedResults(events, league)
+	table := computeStandings(results, rules, nil)
+	remaining := remainingFixtures(events)
+
+	// Teams without a completed match yet still need a row
+	index := make(map[string]int)
+	for i, row := range table {
+		index[row.Team.ID] = i
+	}
+	for _, f := range remaining {
+		for _, t := range []Team{f.home, f.away} {
+			if _, ok := index[t.ID]; !ok {
+				index[t.ID] = len(table)
+				table = append(table, &standingsRow{Team: t})
+			}
+		}
+	}
+
+	fixtures := make([]simFixture, 0, len(remaining))
+	for _, f := range remaining {
+		lh, la := model.expectedGoals(f.home.ID, f.away.ID)
+		fixtures = append(fixtures, simFixture{home: index[f.home.ID], away: index[f.away.ID], lambdaHome: lh, lambdaAway: la})
+	}
+
+	odds := simulateSeason(table, results, rules, fixtures, simRuns, simSeed, simRelegation)
+
+	if format == "json" {
+		out, _ := json.MarshalIndent(odds, "", "  ")
+		fmt.Println(string(out))
+		return nil
+	}
+	displaySeasonOdds(season, odds, len(fixtures))
+	return nil
+}
+
+// fixture is an unplayed match between two teams
+type fixture struct {
+	home, away Team
+}
+
+// remainingFixtures returns the matches of a season that haven't finished
+func remainingFixtures(events []Event) []fixture {
+	var fixtures []fixture
+	for _, event := range events {
+		if event.Status.Type.State == "post" || len(event.Competitions) == 0 {
+			continue
+		}
+		home, away, ok := homeAndAway(event.Competitions[0])
+		if !ok {
+			continue
+		}
+		fixtures = append(fixtures, fixture{home: home.Team, away: away.Team})
+	}
+	return fixtures
+}
+
+// simulateSeason plays the fixtures runs times across all CPUs. Every run
+// draws from its own generator seeded from the run number, so the result
+// only depends on seed and not on how runs are scheduled. Final tables are
+// ordered with the league's tiebreak rules, over the played results plus
+// the run's simulated ones.
+func simulateSeason(table []*standingsRow, played []matchResult, rules []tiebreakRule, fixtures []simFixture, runs int, seed int64, relegation int) []seasonOdds {
+	teams := len(table)
+	workers := runtime.NumCPU()
+	counts := make([][]int, workers)
+	pointTotals := make([][]int, workers)
+
+	index := make(map[string]int, teams)
+	for i, row := range table {
+		index[row.Team.ID] = i
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		counts[w] = make([]int, teams*teams)
+		pointTotals[w] = make([]int, teams)
+
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+
+			rows := make([]standingsRow, teams)
+			order := make([]*standingsRow, teams)
+			results := make([]matchResult, len(played), len(played)+len(fixtures))
+			copy(results, played)
+
+			for run := w; run < runs; run += workers {
+				rng := rand.New(rand.NewSource(seed + int64(run)))
+
+				for i, row := range table {
+					rows[i] = *row
+					order[i] = &rows[i]
+				}
+				results = results[:len(played)]
+
+				for _, f := range fixtures {
+					hg, ag := samplePoisson(rng, f.lambdaHome), samplePoisson(rng, f.lambdaAway)
+					rows[f.home].record(hg, ag)
+					rows[f.away].record(ag, hg)
+					rows[f.away].AwayGoals += ag
+					results = append(results, matchResult{Home: table[f.home].Team, Away: table[f.away].Team, HomeGoals: hg, AwayGoals: ag})
+				}
+
+				sort.Slice(order, func(i, j int) bool {
+					if order[i].Points != order[j].Points {
+						return order[i].Points > order[j].Points
+					}
+					return order[i].Team.DisplayName < order[j].Team.DisplayName
+				})
+				for start := 0; start < teams; {
+					end := start + 1
+					for end < teams && order[end].Points == order[start].Points {
+						end++
+					}
+					breakTies(order[start:end], rules, results)
+					start = end
+				}
+
+				for pos, row := range order {
+					team := index[row.Team.ID]
+					counts[w][team*teams+pos]++
+					pointTotals[w][team] += row.Points
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	odds := make([]seasonOdds, teams)
+	for i, row := range table {
+		o := seasonOdds{Team: row.Team, Points: row.Points, Positions: make([]float64, teams)}
+		var totalPoints int
+		for w := 0; w < workers; w++ {
+			totalPoints += pointTotals[w][i]
+			for pos := 0; pos < teams; pos++ {
+				o.Positions[pos] += float64(counts[w][i*teams+pos]) / float64(runs)
+			}
+		}
+		o.ExpectedPoints = float64(totalPoints) / float64(runs)
+		o.Title = o.Positions[0]
+		for pos := 0; pos < teams; pos++ {
+			if pos < 4 {
+				o.Top4 += o.Positions[pos]
+			}
+			if pos >= teams-relegation {
+				o.Relegation += o.Positions[pos]
+			}
+		}
+		odds[i] = o
+	}
+
+	sort.SliceStable(odds, func(i, j int) bool { return odds[i].ExpectedPoints > odds[j].ExpectedPoints })
+	return odds
+}
+
+// samplePoisson draws a goal count with mean lambda (Knuth's algorithm,
+// which is fine for the small means football produces)
+func samplePoisson(rng *rand.Rand, lambda float64) int {
+	limit := math.Exp(-lambda)
+	k := 0
+	for p := rng.Float64(); p > limit; p *= rng.Float64() {
+		k++
+	}
+	return k
+}
+
+// displaySeasonOdds prints the simulated odds table and position distribution
+func displaySeasonOdds(season Season, odds []seasonOdds, remaining int) {
+	header := color.New(color.FgCyan, color.Bold).SprintFunc()
+	fmt.Printf("\n%s\n", header(fmt.Sprintf("SEASON SIMULATION: %s %s", strings.ToUpper(league), season.DisplayName)))
+	fmt.Println("==========================================================")
+	fmt.Printf("%d runs, %d fixtures remaining, seed %d\n\n", simRuns, remaining, simSeed)
+
+	fmt.Printf("%-4s %-26s %4s %6s %7s %7s %7s\n", "#", "Team", "Pts", "xPts", "Title", "Top 4", "Releg.")
+	for i, o := range odds {
+		fmt.Printf("%-4d %-26s %4d %6.1f %7s %7s %7s\n", i+1, o.Team.DisplayName, o.Points, o.ExpectedPoints,
+			formatPercent(o.Title), formatPercent(o.Top4), formatPercent(o.Relegation))
+	}
+
+	fmt.Printf("\n%s\n", header("FINAL POSITION PROBABILITIES (%)"))
+	fmt.Printf("%-26s", "Team")
+	for pos := range odds {
+		fmt.Printf("%4d", pos+1)
+	}
+	fmt.Println()
+	for _, o := range odds {
+		fmt.Printf("%-26s", o.Team.DisplayName)
+		for _, p := range o.Positions {
+			if p == 0 {
+				fmt.Printf("%4s", ".")
+			} else {
+				fmt.Printf("%4.0f", p*100)
+			}
+		}
+		fmt.Println()
+	}
+}
+
+// formatPercent prints a probability, distinguishing "never" from "rounds to 0"
+func formatPercent(p float64) string {
+	switch {
+	case p == 0:
+		return "-"
+	case p < 0.001:
+		return "<0.1%"
+	default:
+		return fmt.Sprintf("%.1f%%", p*100)
+	}
+}
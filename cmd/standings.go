@@ -0,0 +1,482 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var standingsCmd = &cobra.Command{
+	Use:   "standings",
+	Short: "Show a league table",
+	Long: `The 'standings' command shows a league table. By default it is the table
+published by ESPN; with --computed it is derived from match results using
+the league's own tiebreak rules, which also works when the provider table
+lags behind or is missing.
+
+Examples:
+  # Provider table for the Premier League
+  sharingan standings --league eng.1
+
+  # Table computed from results, with a points deduction
+  sharingan standings --league eng.1 --computed --deduct "Everton=10"
+
+  # Compare the computed table against the provider table
+  sharingan standings --league esp.1 --diff
+
+  # Override the tiebreak order
+  sharingan standings --league eng.1 --computed --tiebreak h2h-points,gd,gf
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return showStandings(cmd.Context())
+	},
+}
+
+// Flags specific to the standings command
+var (
+	computed   bool
+	diffTables bool
+	deductions map[string]int
+	tiebreaks  []string
+)
+
+func init() {
+	rootCmd.AddCommand(standingsCmd)
+
+	standingsCmd.Flags().StringVarP(&league, "league", "l", "", "League slug (e.g. eng.1, esp.1)")
+	standingsCmd.MarkFlagRequired("league")
+	standingsCmd.Flags().BoolVarP(&computed, "computed", "c", false, "Compute the table from match results")
+	standingsCmd.Flags().BoolVar(&diffTables, "diff", false, "Compare the computed table with the provider table")
+	standingsCmd.Flags().StringToIntVar(&deductions, "deduct", nil, "Points deductions by team (e.g. Everton=10)")
+	standingsCmd.Flags().StringSliceVar(&tiebreaks, "tiebreak", nil, "Tiebreak order, overriding the league's rules (e.g. gd,gf,h2h-points)")
+	standingsCmd.Flags().StringVarP(&format, "format", "f", "pretty", "Output format (pretty, json)")
+}
+
+// standingsRow is one team's line in a league table
+type standingsRow struct {
+	Position     int  `json:"position"`
+	Team         Team `json:"team"`
+	Played       int  `json:"played"`
+	Won          int  `json:"won"`
+	Drawn        int  `json:"drawn"`
+	Lost         int  `json:"lost"`
+	GoalsFor     int  `json:"goalsFor"`
+	GoalsAgainst int  `json:"goalsAgainst"`
+	AwayGoals    int  `json:"awayGoals"`
+	FairPlay     int  `json:"fairPlay"`
+	Deduction    int  `json:"deduction,omitempty"`
+	Points       int  `json:"points"`
+}
+
+// GoalDiff returns the row's goal difference
+func (r *standingsRow) GoalDiff() int {
+	return r.GoalsFor - r.GoalsAgainst
+}
+
+// record adds one match to the row
+func (r *standingsRow) record(scored, conceded int) {
+	r.Played++
+	r.GoalsFor += scored
+	r.GoalsAgainst += conceded
+	switch {
+	case scored > conceded:
+		r.Won++
+		r.Points += 3
+	case scored == conceded:
+		r.Drawn++
+		r.Points++
+	default:
+		r.Lost++
+	}
+}
+
+// tiebreakRule scores the teams of a group that is level on points; higher
+// scores rank first. Rules receive the tied group and the season's results
+// so that head-to-head rules can restrict themselves to matches between the
+// tied teams.
+type tiebreakRule struct {
+	Name  string
+	Score func(group []*standingsRow, results []matchResult) map[string]int
+}
+
+// rowRule builds a tiebreak rule from a per-row value
+func rowRule(name string, value func(r *standingsRow) int) tiebreakRule {
+	return tiebreakRule{Name: name, Score: func(group []*standingsRow, _ []matchResult) map[string]int {
+		scores := make(map[string]int, len(group))
+		for _, r := range group {
+			scores[r.Team.ID] = value(r)
+		}
+		return scores
+	}}
+}
+
+// headToHeadRule builds a tiebreak rule from the mini-table of matches
+// played between the tied teams
+func headToHeadRule(name string, value func(r *standingsRow) int) tiebreakRule {
+	return tiebreakRule{Name: name, Score: func(group []*standingsRow, results []matchResult) map[string]int {
+		tied := make(map[string]bool, len(group))
+		for _, r := range group {
+			tied[r.Team.ID] = true
+		}
+		var between []matchResult
+		for _, m := range results {
+			if tied[m.Home.ID] && tied[m.Away.ID] {
+				between = append(between, m)
+			}
+		}
+		mini := tableRows(between)
+
+		scores := make(map[string]int, len(group))
+		for _, r := range group {
+			if row, ok := mini[r.Team.ID]; ok {
+				scores[r.Team.ID] = value(row)
+			}
+		}
+		return scores
+	}}
+}
+
+// tiebreakRules are the rules that can be combined with --tiebreak
+var tiebreakRules = map[string]tiebreakRule{
+	"gd":             rowRule("gd", func(r *standingsRow) int { return r.GoalDiff() }),
+	"gf":             rowRule("gf", func(r *standingsRow) int { return r.GoalsFor }),
+	"wins":           rowRule("wins", func(r *standingsRow) int { return r.Won }),
+	"away-goals":     rowRule("away-goals", func(r *standingsRow) int { return r.AwayGoals }),
+	"fair-play":      rowRule("fair-play", func(r *standingsRow) int { return -r.FairPlay }),
+	"h2h-points":     headToHeadRule("h2h-points", func(r *standingsRow) int { return r.Points }),
+	"h2h-gd":         headToHeadRule("h2h-gd", func(r *standingsRow) int { return r.GoalDiff() }),
+	"h2h-goals":      headToHeadRule("h2h-goals", func(r *standingsRow) int { return r.GoalsFor }),
+	"h2h-away-goals": headToHeadRule("h2h-away-goals", func(r *standingsRow) int { return r.AwayGoals }),
+}
+
+// leagueTiebreaks lists the tiebreak order of leagues that don't use the
+// default of goal difference first
+var leagueTiebreaks = map[string][]string{
+	"eng.1": {"gd", "gf", "h2h-points", "h2h-away-goals"},
+	"esp.1": {"h2h-points", "h2h-gd", "gd", "gf", "fair-play"},
+	"ita.1": {"h2h-points", "h2h-gd", "gd", "gf"},
+	"ger.1": {"gd", "gf", "h2h-points", "h2h-away-goals", "away-goals"},
+	"fra.1": {"gd", "h2h-points", "h2h-gd", "h2h-goals", "gf", "away-goals", "fair-play"},
+}
+
+var defaultTiebreaks = []string{"gd", "gf", "h2h-points"}
+
+// tiebreakRulesFor resolves the tiebreak order for a league, honouring an
+// explicit override
+func tiebreakRulesFor(leagueSlug string, override []string) ([]tiebreakRule, error) {
+	names := override
+	if len(names) == 0 {
+		names = defaultTiebreaks
+		if leagueNames, ok := leagueTiebreaks[strings.ToLower(leagueSlug)]; ok {
+			names = leagueNames
+		}
+	}
+
+	rules := make([]tiebreakRule, 0, len(names))
+	for _, name := range names {
+		rule, ok := tiebreakRules[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			return nil, errInvalidInput("unknown tiebreak rule %q", name)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// tableRows accumulates results into rows keyed by team ID
+func tableRows(results []matchResult) map[string]*standingsRow {
+	rows := make(map[string]*standingsRow)
+	get := func(t Team) *standingsRow {
+		row, ok := rows[t.ID]
+		if !ok {
+			row = &standingsRow{Team: t}
+			rows[t.ID] = row
+		}
+		return row
+	}
+
+	for _, m := range results {
+		home, away := get(m.Home), get(m.Away)
+		home.record(m.HomeGoals, m.AwayGoals)
+		away.record(m.AwayGoals, m.HomeGoals)
+		away.AwayGoals += m.AwayGoals
+		home.FairPlay += m.HomeFairPlay
+		away.FairPlay += m.AwayFairPlay
+	}
+	return rows
+}
+
+// computeStandings derives a league table from match results. Teams level
+// on points are separated by the rules in order; each rule only looks at
+// the teams still tied after the previous one.
+func computeStandings(results []matchResult, rules []tiebreakRule, deductions map[string]int) []*standingsRow {
+	rows := tableRows(results)
+
+	table := make([]*standingsRow, 0, len(rows))
+	for _, row := range rows {
+		if points, ok := deductionFor(row.Team, deductions); ok {
+			row.Deduction = points
+			row.Points -= points
+		}
+		table = append(table, row)
+	}
+
+	sort.Slice(table, func(i, j int) bool {
+		if table[i].Points != table[j].Points {
+			return table[i].Points > table[j].Points
+		}
+		return table[i].Team.DisplayName < table[j].Team.DisplayName
+	})
+
+	for start := 0; start < len(table); {
+		end := start + 1
+		for end < len(table) && table[end].Points == table[start].Points {
+			end++
+		}
+		breakTies(table[start:end], rules, results)
+		start = end
+	}
+
+	for i, row := range table {
+		row.Position = i + 1
+	}
+	return table
+}
+
+// breakTies orders a group of tied teams in place
+func breakTies(group []*standingsRow, rules []tiebreakRule, results []matchResult) {
+	if len(group) < 2 || len(rules) == 0 {
+		return
+	}
+
+	scores := rules[0].Score(group, results)
+	sort.SliceStable(group, func(i, j int) bool {
+		return scores[group[i].Team.ID] > scores[group[j].Team.ID]
+	})
+
+	for start := 0; start < len(group); {
+		end := start + 1
+		for end < len(group) && scores[group[end].Team.ID] == scores[group[start].Team.ID] {
+			end++
+		}
+		breakTies(group[start:end], rules[1:], results)
+		start = end
+	}
+}
+
+// deductionFor finds the points deduction of a team by ID, name or abbreviation
+func deductionFor(t Team, deductions map[string]int) (int, bool) {
+	for key, points := range deductions {
+		if key == t.ID || strings.EqualFold(key, t.DisplayName) || strings.EqualFold(key, t.Abbreviation) ||
+			strings.EqualFold(key, t.ShortDisplayName) {
+			return points, true
+		}
+	}
+	return 0, false
+}
+
+// providerStandings mirrors ESPN's standings endpoint
+type providerStandings struct {
+	Children []struct {
+		Name      string `json:"name"`
+		Standings struct {
+			Entries []struct {
+				Team  Team `json:"team"`
+				Stats []struct {
+					Name  string  `json:"name"`
+					Value float64 `json:"value"`
+				} `json:"stats"`
+			} `json:"entries"`
+		} `json:"standings"`
+	} `json:"children"`
+}
+
+// espnStandingsBase serves league tables; it lives outside the site API tree
+const espnStandingsBase = "https://site.api.espn.com/apis/v2/sports/soccer"
+
+// fetchProviderStandings retrieves the league table published by ESPN
+func fetchProviderStandings(ctx context.Context, leagueSlug string) ([]*standingsRow, error) {
+	body, err := fetchESPN(ctx, fmt.Sprintf("%s/%s/standings", espnStandingsBase, leagueSlug))
+	if err != nil {
+		return nil, err
+	}
+	return parseProviderStandings(leagueSlug, body)
+}
+
+// parseProviderStandings converts ESPN's standings payload into table rows
+func parseProviderStandings(leagueSlug string, body []byte) ([]*standingsRow, error) {
+	var data providerStandings
+	if err := decodeESPN(leagueSlug+" standings", body, &data); err != nil {
+		return nil, err
+	}
+
+	var table []*standingsRow
+	for _, group := range data.Children {
+		for _, entry := range group.Standings.Entries {
+			row := &standingsRow{Team: entry.Team}
+			for _, stat := range entry.Stats {
+				value := int(stat.Value)
+				switch stat.Name {
+				case "rank":
+					row.Position = value
+				case "gamesPlayed":
+					row.Played = value
+				case "wins":
+					row.Won = value
+				case "ties":
+					row.Drawn = value
+				case "losses":
+					row.Lost = value
+				case "pointsFor":
+					row.GoalsFor = value
+				case "pointsAgainst":
+					row.GoalsAgainst = value
+				case "points":
+					row.Points = value
+				case "deductions":
+					row.Deduction = value
+				}
+			}
+			table = append(table, row)
+		}
+	}
+
+	sort.SliceStable(table, func(i, j int) bool { return table[i].Position < table[j].Position })
+	return table, nil
+}
+
+// fetchComputedStandings builds the current season's table from results
+func fetchComputedStandings(ctx context.Context, leagueSlug string, override []string, deductions map[string]int) ([]*standingsRow, error) {
+	rules, err := tiebreakRulesFor(leagueSlug, override)
+	if err != nil {
+		return nil, err
+	}
+
+	_, start, end, err := fetchCurrentSeason(ctx, leagueSlug)
+	if err != nil {
+		return nil, err
+	}
+	if now := time.Now(); end.After(now) {
+		end = now
+	}
+
+	results, err := fetchLeagueResults(ctx, leagueSlug, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return computeStandings(results, rules, deductions), nil
+}
+
+func showStandings(ctx context.Context) error {
+	var table, provider []*standingsRow
+	var err error
+
+	if !computed || diffTables {
+		provider, err = fetchProviderStandings(ctx, league)
+		if err != nil || len(provider) == 0 {
+			if diffTables {
+				if err == nil {
+					err = errNotFound("no provider standings for %s", league)
+				}
+				return fmt.Errorf("fetching provider standings: %w", err)
+			}
+			slog.Info("provider standings unavailable, computing the table from results")
+			computed = true
+		}
+		table = provider
+	}
+
+	if computed || diffTables {
+		table, err = fetchComputedStandings(ctx, league, tiebreaks, deductions)
+		if err != nil {
+			return fmt.Errorf("computing standings: %w", err)
+		}
+	}
+
+	if diffTables {
+		displayStandingsDiff(table, provider)
+		return nil
+	}
+
+	if format == "json" {
+		out, _ := json.MarshalIndent(table, "", "  ")
+		fmt.Println(string(out))
+		return nil
+	}
+
+	if len(table) == 0 {
+		fmt.Println("No standings found.")
+		return nil
+	}
+
+	source := "provider"
+	if computed {
+		source = "computed"
+	}
+	header := color.New(color.FgCyan, color.Bold).SprintFunc()
+	fmt.Printf("\n%s\n", header(fmt.Sprintf("STANDINGS: %s (%s)", strings.ToUpper(league), source)))
+	displayTable(table)
+	return nil
+}
+
+// displayTable prints a league table
+func displayTable(table []*standingsRow) {
+	fmt.Println("==================================================================")
+	fmt.Printf("%-4s %-26s %3s %3s %3s %3s %4s %4s %4s %4s\n", "#", "Team", "P", "W", "D", "L", "GF", "GA", "GD", "Pts")
+	for _, r := range table {
+		points := fmt.Sprintf("%4d", r.Points)
+		if r.Deduction > 0 {
+			points += color.RedString(" (-%d)", r.Deduction)
+		}
+		fmt.Printf("%-4d %-26s %3d %3d %3d %3d %4d %4d %+4d %s\n", r.Position, r.Team.DisplayName,
+			r.Played, r.Won, r.Drawn, r.Lost, r.GoalsFor, r.GoalsAgainst, r.GoalDiff(), points)
+	}
+}
+
+// displayStandingsDiff prints the computed table next to the provider's,
+// highlighting teams whose position, games played or points differ
+func displayStandingsDiff(computedTable, provider []*standingsRow) {
+	byTeam := make(map[string]*standingsRow, len(provider))
+	for _, r := range provider {
+		byTeam[r.Team.ID] = r
+	}
+
+	header := color.New(color.FgCyan, color.Bold).SprintFunc()
+	fmt.Printf("\n%s\n", header(fmt.Sprintf("STANDINGS DIFF: %s (computed vs provider)", strings.ToUpper(league))))
+	fmt.Println("==================================================================")
+	fmt.Printf("%-26s %9s %9s %9s\n", "Team", "Pos", "P", "Pts")
+
+	differences := 0
+	for _, r := range computedTable {
+		p, ok := byTeam[r.Team.ID]
+		if !ok {
+			differences++
+			fmt.Printf("%-26s %s\n", r.Team.DisplayName, color.YellowString("missing from provider table"))
+			continue
+		}
+		delete(byTeam, r.Team.ID)
+
+		line := fmt.Sprintf("%-26s %4d/%-4d %4d/%-4d %4d/%-4d", r.Team.DisplayName,
+			r.Position, p.Position, r.Played, p.Played, r.Points, p.Points)
+		if r.Position != p.Position || r.Played != p.Played || r.Points != p.Points {
+			differences++
+			line = color.YellowString(line)
+		}
+		fmt.Println(line)
+	}
+	for _, p := range provider {
+		if _, ok := byTeam[p.Team.ID]; ok {
+			differences++
+			fmt.Printf("%-26s %s\n", p.Team.DisplayName, color.YellowString("missing from computed table"))
+		}
+	}
+
+	fmt.Printf("\n%d difference(s)\n", differences)
+}
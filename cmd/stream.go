@@ -0,0 +1,275 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// eventHub fans detected match events out to stream subscribers
+type eventHub struct {
+	mu          sync.Mutex
+	subscribers map[*subscriber]struct{}
+	closed      bool
+}
+
+// subscriber is one SSE or WebSocket client and its filters
+type subscriber struct {
+	league string
+	team   string
+	events chan matchEvent
+}
+
+func newEventHub() *eventHub {
+	return &eventHub{subscribers: make(map[*subscriber]struct{})}
+}
+
+// subscribe registers a client. Its channel is closed when the hub shuts down.
+func (h *eventHub) subscribe(league, team string) *subscriber {
+	s := &subscriber{league: league, team: team, events: make(chan matchEvent, 64)}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		close(s.events)
+	} else {
+		h.subscribers[s] = struct{}{}
+	}
+	return s
+}
+
+func (h *eventHub) unsubscribe(s *subscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.subscribers[s]; ok {
+		delete(h.subscribers, s)
+		close(s.events)
+	}
+}
+
+// publish delivers events to every interested subscriber. A subscriber that
+// isn't keeping up misses events rather than holding up everyone else.
+func (h *eventHub) publish(events []matchEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for s := range h.subscribers {
+		for _, e := range events {
+			if !s.wants(e) {
+				continue
+			}
+			select {
+			case s.events <- e:
+			default:
+			}
+		}
+	}
+}
+
+// count returns the number of connected subscribers
+func (h *eventHub) count() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subscribers)
+}
+
+// close disconnects every subscriber
+func (h *eventHub) close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for s := range h.subscribers {
+		delete(h.subscribers, s)
+		close(s.events)
+	}
+}
+
+func (s *subscriber) wants(e matchEvent) bool {
+	if s.league != "" && !strings.EqualFold(e.Match.League, s.league) && !strings.EqualFold(e.Match.LeagueName, s.league) {
+		return false
+	}
+	return e.involves(s.team)
+}
+
+// pollEvents fetches the scoreboard every interval and publishes the
+// events detected between polls until ctx is cancelled. Goals go through
+// confirm first, unless it is nil.
+func pollEvents(ctx context.Context, p provider, stats *pollStats, hub *eventHub, confirm *goalConfirmer) {
+	detector := newEventDetector()
+	ticker := time.NewTicker(stats.interval)
+	defer ticker.Stop()
+
+	for {
+		events, err := p.Scoreboard(ctx, "all", "")
+		if err != nil {
+			if ctx.Err() != nil {
+				return // interrupted
+			}
+			slog.Error("polling scoreboard failed", "err", err)
+		} else {
+			detected := detector.update(events)
+			if confirm != nil {
+				detected = confirm.filter(ctx, detected, detector.last)
+			}
+			hub.publish(detected)
+		}
+		stats.lastPoll.Store(time.Now().UnixNano())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// goalConfirmer holds goals back until a second source shows the same
+// score, so a phantom score in one feed never reaches subscribers
+type goalConfirmer struct {
+	source  provider
+	timeout time.Duration
+	pending []matchEvent
+	// sameProvider is set when source is the polled provider itself; only
+	// its per-league feeds are then a second opinion, so goals without a
+	// league can't be confirmed
+	sameProvider bool
+}
+
+// filter returns the events to publish now: everything but goals, plus the
+// held goals the second source confirms. current is the first source's
+// latest view of every match.
+func (c *goalConfirmer) filter(ctx context.Context, events []matchEvent, current map[string]apiMatch) []matchEvent {
+	var ready []matchEvent
+	for _, e := range events {
+		if e.Type == eventGoal {
+			c.pending = append(c.pending, e)
+		} else {
+			ready = append(ready, e)
+		}
+	}
+
+	boards := make(map[string][]apiMatch) // league -> second source's matches
+	var held []matchEvent
+	for _, goal := range c.pending {
+		latest, ok := current[goal.Match.ID]
+		if !ok || scoreBelow(latest, goal.Match) {
+			slog.Warn("dropping goal the first source no longer shows", "goal", goal)
+			continue
+		}
+
+		if slug := goal.Match.League; slug != "" || !c.sameProvider {
+			slug = defaultIfEmpty(slug, "all")
+			others, fetched := boards[slug]
+			if !fetched {
+				events, err := c.source.Scoreboard(ctx, slug, "")
+				if err != nil {
+					slog.Warn("confirming goals failed", "source", c.source.Name(), "err", err)
+				}
+				for _, event := range events {
+					others = append(others, normalizeMatch(event))
+				}
+				boards[slug] = others
+			}
+
+			if i := pairMatch(goal.Match, others, make([]bool, len(others))); i >= 0 && !scoreBelow(others[i], goal.Match) {
+				ready = append(ready, goal)
+				continue
+			}
+		}
+		if time.Since(goal.Time) > c.timeout {
+			slog.Warn("dropping unconfirmed goal", "source", c.source.Name(), "after", c.timeout, "goal", goal)
+			continue
+		}
+		held = append(held, goal)
+	}
+	c.pending = held
+	return ready
+}
+
+// scoreBelow reports whether either side of m has fewer goals than in target
+func scoreBelow(m, target apiMatch) bool {
+	below := func(score, want *int) bool {
+		return want != nil && (score == nil || *score < *want)
+	}
+	return below(m.Home.Score, target.Home.Score) || below(m.Away.Score, target.Away.Score)
+}
+
+// streamHeartbeat keeps idle connections from being closed by proxies
+const streamHeartbeat = 15 * time.Second
+
+// handleSSE streams match events as Server-Sent Events
+func (s *apiServer) handleSSE(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("streaming not supported"))
+		return
+	}
+
+	sub := s.hub.subscribe(r.URL.Query().Get("league"), r.URL.Query().Get("team"))
+	defer s.hub.unsubscribe(sub)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, "retry: 5000\n\n")
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+		case e, ok := <-sub.events:
+			if !ok {
+				return
+			}
+			data, _ := json.Marshal(e)
+			fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data)
+		}
+		flusher.Flush()
+	}
+}
+
+// handleWebSocket streams match events as WebSocket text messages
+func (s *apiServer) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	ws, err := upgradeWebSocket(w, r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	defer ws.Close()
+
+	sub := s.hub.subscribe(r.URL.Query().Get("league"), r.URL.Query().Get("team"))
+	defer s.hub.unsubscribe(sub)
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ws.Done():
+			return
+		case <-heartbeat.C:
+			if err := ws.Ping(); err != nil {
+				return
+			}
+		case e, ok := <-sub.events:
+			if !ok {
+				return
+			}
+			data, _ := json.Marshal(e)
+			if err := ws.WriteText(data); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -1,12 +1,10 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
-	"log"
-	"net/http"
-	"os"
+	"log/slog"
 	"strings"
 	"time"
 
@@ -26,9 +24,12 @@ Examples:
 
   # Get information about a team with abbreviation
   sharingan team --name MUN
+
+  # Include the team's Elo rating within its league
+  sharingan team --name Arsenal --league eng.1
 `,
-	Run: func(cmd *cobra.Command, args []string) {
-		fetchTeamInfo()
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return fetchTeamInfo(cmd.Context())
 	},
 }
 
@@ -38,133 +39,41 @@ func init() {
 	teamCmd.Flags().StringVarP(&team, "name", "n", "", "Team name or abbreviation")
 	teamCmd.MarkFlagRequired("name")
 	teamCmd.Flags().StringVarP(&format, "format", "f", "pretty", "Output format (pretty, json)")
+	teamCmd.Flags().StringVarP(&league, "league", "l", "", "League slug used for the Elo rating (e.g. eng.1)")
 }
 
-func fetchTeamInfo() {
+func fetchTeamInfo(ctx context.Context) error {
 	if team == "" {
-		fmt.Println("Please provide a team name or abbreviation using the --name flag")
-		return
-	}
-
-	// First search for the team ID
-	searchURL := fmt.Sprintf("https://site.api.espn.com/apis/site/v2/sports/soccer/all/teams?limit=1000")
-
-	client := &http.Client{Timeout: 30 * time.Second}
-	req, err := http.NewRequest("GET", searchURL, nil)
-	if err != nil {
-		log.Fatalf("Error creating request: %v", err)
-	}
-
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/114.0.0.0 Safari/537.36")
-	req.Header.Set("Accept", "application/json")
-
-	fmt.Printf("Searching for team: %s...\n", team)
-
-	resp, err := client.Do(req)
-	if err != nil {
-		log.Fatalf("Error fetching data: %v", err)
+		return errInvalidInput("please provide a team name or abbreviation using the --name flag")
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		log.Fatalf("Error reading response: %v", err)
-	}
-
-	// For debugging
-	if os.Getenv("DEBUG") == "true" {
-		if err := os.WriteFile("espn_teams_response.json", body, 0644); err != nil {
-			log.Printf("Warning: Failed to save response to file: %v", err)
-		}
-	}
+	slog.Info("searching for team", "team", team)
 
-	type TeamsResponse struct {
-		Sports []struct {
-			Leagues []struct {
-				Teams []struct {
-					Team Team `json:"team"`
-				} `json:"teams"`
-			} `json:"leagues"`
-		} `json:"sports"`
-	}
-
-	var teamsData TeamsResponse
-	err = json.Unmarshal(body, &teamsData)
+	// First search for the team ID
+	foundTeam, teamFound, err := findTeam(ctx, team)
 	if err != nil {
-		log.Printf("Error parsing JSON: %v", err)
-		fmt.Printf("Response sample: %s\n", string(body[:min(1000, len(body))]))
-		return
-	}
-
-	// Find the team
-	var foundTeam Team
-	var teamFound bool
-
-	searchTerm := strings.ToLower(team)
-	for _, sport := range teamsData.Sports {
-		for _, league := range sport.Leagues {
-			for _, t := range league.Teams {
-				teamName := strings.ToLower(t.Team.DisplayName)
-				teamAbbrev := strings.ToLower(t.Team.Abbreviation)
-
-				if strings.Contains(teamName, searchTerm) || teamAbbrev == searchTerm {
-					foundTeam = t.Team
-					teamFound = true
-					break
-				}
-			}
-			if teamFound {
-				break
-			}
-		}
-		if teamFound {
-			break
-		}
+		return err
 	}
 
 	if !teamFound {
-		fmt.Printf("Team '%s' not found. Please check the name or abbreviation.\n", team)
-		return
+		return errNotFound("team '%s' not found. Please check the name or abbreviation.", team)
 	}
 
 	// Now fetch detailed team info using the ID
 	teamURL := fmt.Sprintf("https://site.api.espn.com/apis/site/v2/sports/soccer/all/teams/%s", foundTeam.ID)
-	req, err = http.NewRequest("GET", teamURL, nil)
-	if err != nil {
-		log.Fatalf("Error creating request: %v", err)
-	}
-
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/114.0.0.0 Safari/537.36")
-	req.Header.Set("Accept", "application/json")
-
-	resp, err = client.Do(req)
+	body, err := fetchESPN(ctx, teamURL)
 	if err != nil {
-		log.Fatalf("Error fetching team data: %v", err)
-	}
-	defer resp.Body.Close()
-
-	body, err = io.ReadAll(resp.Body)
-	if err != nil {
-		log.Fatalf("Error reading response: %v", err)
+		return err
 	}
 
 	if format == "json" {
 		fmt.Println(string(body))
-		return
-	}
-
-	// For debugging
-	if os.Getenv("DEBUG") == "true" {
-		if err := os.WriteFile("espn_team_detail.json", body, 0644); err != nil {
-			log.Printf("Warning: Failed to save response to file: %v", err)
-		}
+		return nil
 	}
 
 	var teamData map[string]interface{}
-	err = json.Unmarshal(body, &teamData)
-	if err != nil {
-		log.Printf("Error parsing team JSON: %v", err)
-		return
+	if err := json.Unmarshal(body, &teamData); err != nil {
+		return &decodeError{"team", err}
 	}
 
 	// Display team info
@@ -179,6 +88,11 @@ func fetchTeamInfo() {
 		fmt.Printf("Logo URL: %s\n", foundTeam.Logo)
 	}
 
+	// Show the Elo rating when a league is given
+	if league != "" {
+		displayTeamRating(ctx, foundTeam.ID, league)
+	}
+
 	// Extract and display next match
 	nextMatch, hasNextMatch := teamData["nextEvent"]
 	if hasNextMatch && len(nextMatch.([]interface{})) > 0 {
@@ -225,6 +139,15 @@ func fetchTeamInfo() {
 					fmt.Printf("Match: %s vs %s\n",
 						homeTeamData["displayName"].(string),
 						awayTeamData["displayName"].(string))
+
+					// Estimate the outcome when the league is known
+					homeID, _ := homeTeamData["id"].(string)
+					awayID, _ := awayTeamData["id"].(string)
+					if prediction, ok := predictionFor(ctx, league,
+						Team{ID: homeID, DisplayName: homeTeamData["displayName"].(string)},
+						Team{ID: awayID, DisplayName: awayTeamData["displayName"].(string)}); ok {
+						displayPrediction(prediction)
+					}
 				}
 			}
 
@@ -270,33 +193,14 @@ func fetchTeamInfo() {
 	resultsURL := fmt.Sprintf("https://site.api.espn.com/apis/site/v2/sports/soccer/all/teams/%s/schedule?dates=%s-%s",
 		foundTeam.ID, startDate, endDate)
 
-	req, err = http.NewRequest("GET", resultsURL, nil)
-	if err != nil {
-		fmt.Println("Error fetching recent results")
-		return
-	}
-
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/114.0.0.0 Safari/537.36")
-	req.Header.Set("Accept", "application/json")
-
-	resp, err = client.Do(req)
+	body, err = fetchESPN(ctx, resultsURL)
 	if err != nil {
-		fmt.Println("Error fetching recent results")
-		return
-	}
-	defer resp.Body.Close()
-
-	body, err = io.ReadAll(resp.Body)
-	if err != nil {
-		fmt.Println("Error reading results response")
-		return
+		return err
 	}
 
 	var scheduleData ESPNResponse
-	err = json.Unmarshal(body, &scheduleData)
-	if err != nil {
-		fmt.Println("No recent matches found")
-		return
+	if err := decodeESPN("team schedule", body, &scheduleData); err != nil {
+		return err
 	}
 
 	var recentMatches []Event
@@ -309,6 +213,55 @@ func fetchTeamInfo() {
 	if len(recentMatches) == 0 {
 		fmt.Println("No recent matches found")
 	} else {
-		displayMatches(recentMatches, "completed")
+		displayMatches(ctx, recentMatches, "completed")
+	}
+	return nil
+}
+
+// findTeam searches ESPN's soccer teams by name or abbreviation
+func findTeam(ctx context.Context, name string) (Team, bool, error) {
+	body, err := fetchESPN(ctx, espnSoccerBase+"/all/teams?limit=1000")
+	if err != nil {
+		return Team{}, false, err
+	}
+
+	var teamsData TeamsResponse
+	if err := decodeESPN("teams", body, &teamsData); err != nil {
+		return Team{}, false, err
+	}
+
+	searchTerm := strings.ToLower(name)
+	for _, sport := range teamsData.Sports {
+		for _, league := range sport.Leagues {
+			for _, t := range league.Teams {
+				teamName := strings.ToLower(t.Team.DisplayName)
+				teamAbbrev := strings.ToLower(t.Team.Abbreviation)
+
+				if strings.Contains(teamName, searchTerm) || teamAbbrev == searchTerm {
+					return t.Team, true, nil
+				}
+			}
+		}
+	}
+	return Team{}, false, nil
+}
+
+// fetchTeamSchedule retrieves a team's schedule. With fixtures set it returns
+// upcoming matches, otherwise the results played so far this season.
+func fetchTeamSchedule(ctx context.Context, teamID string, fixtures bool) ([]Event, error) {
+	url := fmt.Sprintf("%s/all/teams/%s/schedule", espnSoccerBase, teamID)
+	if fixtures {
+		url += "?fixture=true"
+	}
+
+	body, err := fetchESPN(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	var data ESPNResponse
+	if err := decodeESPN("team schedule", body, &data); err != nil {
+		return nil, err
 	}
+	return data.Events, nil
 }
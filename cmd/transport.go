@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Retry and rate limiting settings for upstream requests, set by the
+// --retries and --rps flags
+var (
+	maxRetries     int
+	requestsPerSec float64
+)
+
+const (
+	// attemptTimeout bounds the wait for each attempt's response headers;
+	// requestTimeout bounds a whole request, retries included
+	attemptTimeout = 30 * time.Second
+	requestTimeout = 3 * time.Minute
+
+	retryBaseDelay = 500 * time.Millisecond
+	retryMaxDelay  = 30 * time.Second
+	// maxRetryAfter is the longest Retry-After we wait for; a longer one
+	// fails the request instead of blocking the command
+	maxRetryAfter = time.Minute
+)
+
+var (
+	espnClientOnce sync.Once
+	espnHTTPClient *http.Client
+)
+
+// espnClient returns the client shared by every upstream request, so the
+// rate limit applies across all goroutines
+func espnClient() *http.Client {
+	espnClientOnce.Do(func() {
+		next := http.DefaultTransport.(*http.Transport).Clone()
+		next.ResponseHeaderTimeout = attemptTimeout
+		espnHTTPClient = &http.Client{
+			Timeout: requestTimeout,
+			Transport: &retryTransport{
+				next:    harTransport(next),
+				retries: maxRetries,
+				limiter: newRateLimiter(requestsPerSec),
+			},
+		}
+	})
+	return espnHTTPClient
+}
+
+// retryTransport retries idempotent requests that fail with a network error
+// or a retryable status, with capped exponential backoff and jitter. Every
+// attempt, retries included, waits for the rate limiter.
+type retryTransport struct {
+	next    http.RoundTripper
+	retries int
+	limiter *rateLimiter
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	idempotent := req.Method == http.MethodGet || req.Method == http.MethodHead
+
+	for attempt := 0; ; attempt++ {
+		if err := t.limiter.wait(ctx); err != nil {
+			return nil, err
+		}
+
+		start := time.Now()
+		resp, err := t.next.RoundTrip(req)
+		code := 0
+		if err == nil {
+			code = resp.StatusCode
+		}
+		elapsed := time.Since(start)
+		recordUpstream(req.URL.String(), code, elapsed)
+		slog.Debug("upstream request", "url", req.URL.String(), "attempt", attempt+1, "status", code, "elapsed", elapsed, "err", err)
+
+		if !idempotent || attempt >= t.retries || !shouldRetry(ctx, resp, err) {
+			return resp, err
+		}
+
+		delay := backoffDelay(attempt)
+		if resp != nil {
+			if after, ok := retryAfter(resp); ok {
+				if after > maxRetryAfter {
+					return resp, nil
+				}
+				delay = after
+			}
+			// Drain the body so the connection can be reused
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+		upstreamRetries.inc(upstreamEndpoint(req.URL.String()))
+		slog.Info("retrying upstream request", "url", req.URL.String(), "in", delay)
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// shouldRetry reports whether a failed attempt is worth repeating
+func shouldRetry(ctx context.Context, resp *http.Response, err error) bool {
+	if err != nil {
+		// A cancelled or expired request won't get any better
+		return ctx.Err() == nil && !errors.Is(err, context.Canceled)
+	}
+	return (&httpError{StatusCode: resp.StatusCode}).retryable()
+}
+
+// backoffDelay is the wait before retry number attempt+1: exponential,
+// capped, with jitter so concurrent clients don't retry in lockstep
+func backoffDelay(attempt int) time.Duration {
+	delay := retryMaxDelay
+	if attempt < 16 {
+		delay = retryBaseDelay << attempt
+	}
+	if delay > retryMaxDelay {
+		delay = retryMaxDelay
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP date
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		delay := time.Until(at)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+	return 0, false
+}
+
+// rateLimiter spaces requests evenly to stay within a requests-per-second
+// budget. It is safe for concurrent use; a nil limiter doesn't limit.
+type rateLimiter struct {
+	interval time.Duration
+
+	mu   sync.Mutex
+	next time.Time // earliest time the next request may start
+}
+
+func newRateLimiter(perSecond float64) *rateLimiter {
+	if perSecond <= 0 {
+		return nil
+	}
+	return &rateLimiter{interval: time.Duration(float64(time.Second) / perSecond)}
+}
+
+// wait blocks until the caller may make a request
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	delay := l.next.Sub(now)
+	l.next = l.next.Add(l.interval)
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(delay):
+		return nil
+	}
+}
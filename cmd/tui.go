@@ -0,0 +1,731 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// tuiCmd represents the tui command
+var tuiCmd = &cobra.Command{
+	Use:   "tui",
+	Short: "Full-screen live scores dashboard",
+	Long: `The 'tui' command opens a full-screen dashboard of today's matches.
+Leagues are listed in a sidebar, matches are grouped into live, upcoming and
+completed and refresh in the background, and pressing enter on a match opens
+its summary.
+
+Keys:
+  up/down, j/k     Move the selection
+  left/right, tab  Switch between the sidebar and the match list
+  enter            Open the selected match (or league)
+  esc              Close the match summary or clear the search
+  /                Search teams and matches
+  f / F            Pin or unpin the home / away team as a favourite
+  r                Refresh now
+  q, ctrl-c        Quit
+
+Favourites are stored in the config file and always listed first.
+
+Examples:
+  # Open the dashboard
+  sharingan tui
+
+  # Refresh every 10 seconds
+  sharingan tui --interval 10s
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runTUI(cmd.Context())
+	},
+}
+
+var refreshInterval time.Duration
+
+func init() {
+	rootCmd.AddCommand(tuiCmd)
+
+	tuiCmd.Flags().DurationVar(&refreshInterval, "interval", 30*time.Second, "How often to refresh the scores")
+}
+
+// Panes of the dashboard that can have focus
+const (
+	paneSidebar = iota
+	paneMatches
+)
+
+// Sidebar entries that aren't league names
+const (
+	allLeagues = "All leagues"
+	favourites = "Favourites"
+)
+
+// Keys decoded from terminal input
+const (
+	keyUp = iota + 1
+	keyDown
+	keyLeft
+	keyRight
+	keyEnter
+	keyEscape
+	keyTab
+	keyBackspace
+	keyQuit
+	keyRune
+)
+
+type keyPress struct {
+	key  int
+	char rune
+}
+
+// tuiRow is a line of the match list: a group heading or a match
+type tuiRow struct {
+	heading string
+	event   *Event
+}
+
+// tuiApp holds the dashboard state. It is only touched from the main loop.
+type tuiApp struct {
+	cfg    *config
+	events []Event
+
+	leagues   []string
+	leagueIdx int
+	focus     int
+	cursor    int // index into the selectable match rows
+	offset    int // first visible row of the match list
+
+	searching bool
+	search    string
+
+	detail    []string
+	detailFor string
+
+	status  string
+	updated time.Time
+	width   int
+	height  int
+}
+
+type scoresResult struct {
+	events []Event
+	err    error
+}
+
+type summaryResult struct {
+	id    string
+	lines []string
+	err   error
+}
+
+func runTUI(ctx context.Context) error {
+	fd := int(os.Stdin.Fd())
+	width, height, err := terminalSize(fd)
+	if err != nil {
+		return fmt.Errorf("tui needs an interactive terminal: %w", err)
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	restore, err := makeRaw(fd)
+	if err != nil {
+		return err
+	}
+	defer restore()
+
+	// Switch to the alternate screen and hide the cursor
+	fmt.Print("\x1b[?1049h\x1b[?25l")
+	defer fmt.Print("\x1b[?25h\x1b[?1049l")
+
+	app := &tuiApp{cfg: cfg, focus: paneMatches, width: width, height: height, status: "Loading..."}
+
+	keys := make(chan keyPress)
+	go readKeys(keys)
+
+	resize := make(chan os.Signal, 1)
+	notifyResize(resize)
+
+	scores := make(chan scoresResult, 1)
+	summaries := make(chan summaryResult, 1)
+	refresh := func() {
+		go func() {
+			events, err := fetchTodayEvents(ctx)
+			scores <- scoresResult{events, err}
+		}()
+	}
+	refresh()
+
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		app.draw()
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			refresh()
+		case <-resize:
+			if w, h, err := terminalSize(fd); err == nil {
+				app.width, app.height = w, h
+			}
+		case res := <-scores:
+			if res.err != nil {
+				app.status = fmt.Sprintf("Refresh failed: %v", res.err)
+				continue
+			}
+			app.setEvents(res.events)
+			app.updated = time.Now()
+			app.status = takeFallbackNotice()
+		case res := <-summaries:
+			if res.id != app.detailFor {
+				continue
+			}
+			if res.err != nil {
+				app.detail = []string{fmt.Sprintf("Could not load summary: %v", res.err)}
+			} else {
+				app.detail = res.lines
+			}
+		case k := <-keys:
+			switch {
+			case k.key == keyQuit:
+				return nil
+			case app.searching:
+				app.handleSearchKey(k)
+			case k.key == keyRune && k.char == 'q':
+				return nil
+			case k.key == keyRune && k.char == 'r':
+				app.status = "Refreshing..."
+				refresh()
+			case k.key == keyEnter && app.focus == paneMatches:
+				if event := app.selected(); event != nil {
+					app.detailFor = event.ID
+					app.detail = []string{"Loading summary..."}
+					go func(event Event) {
+						lines, err := summaryLines(ctx, event)
+						summaries <- summaryResult{event.ID, lines, err}
+					}(*event)
+				}
+			default:
+				app.handleKey(k)
+			}
+		}
+	}
+}
+
+// readKeys decodes terminal input into key presses
+func readKeys(keys chan<- keyPress) {
+	buf := make([]byte, 64)
+	for {
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			keys <- keyPress{key: keyQuit}
+			return
+		}
+
+		in := buf[:n]
+		for len(in) > 0 {
+			switch {
+			case len(in) >= 3 && in[0] == 0x1b && (in[1] == '[' || in[1] == 'O'):
+				switch in[2] {
+				case 'A':
+					keys <- keyPress{key: keyUp}
+				case 'B':
+					keys <- keyPress{key: keyDown}
+				case 'C':
+					keys <- keyPress{key: keyRight}
+				case 'D':
+					keys <- keyPress{key: keyLeft}
+				}
+				in = in[3:]
+			case in[0] == 0x1b:
+				keys <- keyPress{key: keyEscape}
+				in = in[1:]
+			case in[0] == 3:
+				keys <- keyPress{key: keyQuit}
+				in = in[1:]
+			case in[0] == '\r' || in[0] == '\n':
+				keys <- keyPress{key: keyEnter}
+				in = in[1:]
+			case in[0] == '\t':
+				keys <- keyPress{key: keyTab}
+				in = in[1:]
+			case in[0] == 127 || in[0] == 8:
+				keys <- keyPress{key: keyBackspace}
+				in = in[1:]
+			default:
+				r, size := utf8.DecodeRune(in)
+				keys <- keyPress{key: keyRune, char: r}
+				in = in[size:]
+			}
+		}
+	}
+}
+
+// setEvents replaces the match data, keeping the selected league and match
+func (a *tuiApp) setEvents(events []Event) {
+	var selectedID string
+	if event := a.selected(); event != nil {
+		selectedID = event.ID
+	}
+	currentLeague := a.league()
+
+	a.events = events
+
+	seen := make(map[string]bool)
+	var names []string
+	for _, event := range events {
+		name := tuiLeagueName(event)
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	a.leagues = append([]string{allLeagues, favourites}, names...)
+
+	a.leagueIdx = 0
+	for i, name := range a.leagues {
+		if name == currentLeague {
+			a.leagueIdx = i
+		}
+	}
+
+	a.cursor = 0
+	for i, event := range a.matches() {
+		if event.ID == selectedID {
+			a.cursor = i
+		}
+	}
+}
+
+func tuiLeagueName(event Event) string {
+	return defaultIfEmpty(event.League.Name, "Other")
+}
+
+func (a *tuiApp) league() string {
+	if a.leagueIdx < len(a.leagues) {
+		return a.leagues[a.leagueIdx]
+	}
+	return allLeagues
+}
+
+func (a *tuiApp) handleKey(k keyPress) {
+	switch {
+	case k.key == keyUp || k.key == keyRune && k.char == 'k':
+		a.move(-1)
+	case k.key == keyDown || k.key == keyRune && k.char == 'j':
+		a.move(1)
+	case k.key == keyLeft || k.key == keyRune && k.char == 'h':
+		a.focus = paneSidebar
+	case k.key == keyRight || k.key == keyRune && k.char == 'l':
+		a.focus = paneMatches
+	case k.key == keyTab:
+		a.focus = 1 - a.focus
+	case k.key == keyEnter:
+		a.focus = paneMatches
+	case k.key == keyEscape:
+		if a.detail != nil {
+			a.detail, a.detailFor = nil, ""
+		} else {
+			a.search = ""
+		}
+	case k.key == keyRune && k.char == '/':
+		a.searching = true
+		a.focus = paneMatches
+	case k.key == keyRune && (k.char == 'f' || k.char == 'F'):
+		a.toggleFavourite(k.char == 'F')
+	}
+}
+
+func (a *tuiApp) handleSearchKey(k keyPress) {
+	switch k.key {
+	case keyEnter:
+		a.searching = false
+	case keyEscape:
+		a.searching = false
+		a.search = ""
+	case keyBackspace:
+		if a.search != "" {
+			_, size := utf8.DecodeLastRuneInString(a.search)
+			a.search = a.search[:len(a.search)-size]
+		}
+	case keyRune:
+		a.search += string(k.char)
+	}
+	a.cursor = 0
+}
+
+func (a *tuiApp) move(delta int) {
+	if a.focus == paneSidebar {
+		a.leagueIdx = clampIndex(a.leagueIdx+delta, len(a.leagues))
+		a.cursor = 0
+		return
+	}
+	a.cursor = clampIndex(a.cursor+delta, len(a.matches()))
+}
+
+func clampIndex(i, n int) int {
+	if i >= n {
+		i = n - 1
+	}
+	if i < 0 {
+		i = 0
+	}
+	return i
+}
+
+func (a *tuiApp) toggleFavourite(away bool) {
+	event := a.selected()
+	if event == nil {
+		return
+	}
+	home, awaySide, ok := homeAndAway(event.Competitions[0])
+	if !ok {
+		return
+	}
+	t := home.Team
+	if away {
+		t = awaySide.Team
+	}
+
+	verb := "Unpinned"
+	if a.cfg.toggleFavourite(t) {
+		verb = "Pinned"
+	}
+	if err := a.cfg.save(); err != nil {
+		a.status = fmt.Sprintf("Could not save favourites: %v", err)
+		return
+	}
+	a.status = fmt.Sprintf("%s %s", verb, t.DisplayName)
+}
+
+// isFavouriteMatch reports whether either side is a favourite team
+func (a *tuiApp) isFavouriteMatch(event Event) bool {
+	home, away, ok := homeAndAway(event.Competitions[0])
+	return ok && (a.cfg.isFavourite(home.Team) || a.cfg.isFavourite(away.Team))
+}
+
+// visible returns the events passing the league and search filters
+func (a *tuiApp) visible() []Event {
+	league := a.league()
+	search := strings.ToLower(a.search)
+
+	var events []Event
+	for _, event := range a.events {
+		if len(event.Competitions) == 0 {
+			continue
+		}
+		switch league {
+		case allLeagues:
+		case favourites:
+			if !a.isFavouriteMatch(event) {
+				continue
+			}
+		default:
+			if tuiLeagueName(event) != league {
+				continue
+			}
+		}
+		if search != "" && !strings.Contains(strings.ToLower(event.Name+" "+event.ShortName), search) {
+			continue
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+// rows lays out the match list: each state group under a heading, with
+// favourite matches first within the group
+func (a *tuiApp) rows() []tuiRow {
+	live, upcoming, completed := groupMatchesByState(a.visible())
+
+	var rows []tuiRow
+	for _, group := range []struct {
+		heading string
+		events  []Event
+	}{
+		{"LIVE", live},
+		{"UPCOMING", upcoming},
+		{"COMPLETED", completed},
+	} {
+		if len(group.events) == 0 {
+			continue
+		}
+		sort.SliceStable(group.events, func(i, j int) bool {
+			return a.isFavouriteMatch(group.events[i]) && !a.isFavouriteMatch(group.events[j])
+		})
+		rows = append(rows, tuiRow{heading: fmt.Sprintf("%s (%d)", group.heading, len(group.events))})
+		for i := range group.events {
+			rows = append(rows, tuiRow{event: &group.events[i]})
+		}
+	}
+	return rows
+}
+
+// matches returns the selectable matches in display order
+func (a *tuiApp) matches() []*Event {
+	var events []*Event
+	for _, row := range a.rows() {
+		if row.event != nil {
+			events = append(events, row.event)
+		}
+	}
+	return events
+}
+
+func (a *tuiApp) selected() *Event {
+	events := a.matches()
+	if a.cursor < len(events) {
+		return events[a.cursor]
+	}
+	return nil
+}
+
+// sidebarWidth is the width of the league list, including its border
+const sidebarWidth = 26
+
+// draw renders the whole screen
+func (a *tuiApp) draw() {
+	if a.width < sidebarWidth+20 || a.height < 6 {
+		fmt.Print("\x1b[H\x1b[2JTerminal too small")
+		return
+	}
+
+	title := color.New(color.Bold, color.ReverseVideo).SprintFunc()
+	dim := color.New(color.Faint).SprintFunc()
+	highlight := color.New(color.ReverseVideo).SprintFunc()
+	headings := map[string]func(...interface{}) string{
+		"LIVE":      color.New(color.FgRed, color.Bold).SprintFunc(),
+		"UPCOMING":  color.New(color.FgYellow, color.Bold).SprintFunc(),
+		"COMPLETED": color.New(color.FgGreen, color.Bold).SprintFunc(),
+	}
+
+	bodyHeight := a.height - 2
+	listWidth := a.width - sidebarWidth
+	listHeight := bodyHeight
+	if a.detail != nil {
+		listHeight = bodyHeight / 2
+	}
+
+	// Sidebar
+	sidebar := make([]string, bodyHeight)
+	for i := range sidebar {
+		line := ""
+		if i < len(a.leagues) {
+			line = " " + a.leagues[i]
+		}
+		line = padRight(line, sidebarWidth-1)
+		if i == a.leagueIdx {
+			if a.focus == paneSidebar {
+				line = highlight(line)
+			} else {
+				line = color.New(color.Bold).Sprint(line)
+			}
+		}
+		sidebar[i] = line + dim("│")
+	}
+
+	// Match list, scrolled to keep the selection visible
+	rows := a.rows()
+	selectedRow := -1
+	match := 0
+	for i, row := range rows {
+		if row.event != nil {
+			if match == a.cursor {
+				selectedRow = i
+			}
+			match++
+		}
+	}
+	if selectedRow >= 0 {
+		if selectedRow < a.offset {
+			a.offset = selectedRow
+			if a.offset > 0 && rows[a.offset-1].event == nil {
+				a.offset--
+			}
+		}
+		if selectedRow >= a.offset+listHeight {
+			a.offset = selectedRow - listHeight + 1
+		}
+	}
+	if a.offset > len(rows) {
+		a.offset = 0
+	}
+
+	list := make([]string, bodyHeight)
+	for i := 0; i < listHeight; i++ {
+		r := a.offset + i
+		if r >= len(rows) {
+			list[i] = padRight("", listWidth)
+			continue
+		}
+		row := rows[r]
+		if row.event == nil {
+			name := strings.Fields(row.heading)[0]
+			list[i] = headings[name](padRight(" "+row.heading, listWidth))
+			continue
+		}
+		line := padRight(" "+a.matchLine(*row.event), listWidth)
+		if r == selectedRow && a.focus == paneMatches {
+			line = highlight(line)
+		}
+		list[i] = line
+	}
+	if len(rows) == 0 && listHeight > 0 {
+		list[0] = padRight(" No matches", listWidth)
+	}
+
+	// Detail pane under the list
+	if a.detail != nil {
+		list[listHeight] = dim(strings.Repeat("─", listWidth))
+		for i := listHeight + 1; i < bodyHeight; i++ {
+			line := ""
+			if d := i - listHeight - 1; d < len(a.detail) {
+				line = " " + a.detail[d]
+			}
+			list[i] = padRight(line, listWidth)
+		}
+	}
+
+	var screen strings.Builder
+	screen.WriteString("\x1b[H")
+
+	header := " Sharingan - today's football"
+	if !a.updated.IsZero() {
+		header += " - updated " + a.updated.Format("15:04:05")
+	}
+	screen.WriteString(title(padRight(header, a.width)))
+	screen.WriteString("\r\n")
+
+	for i := 0; i < bodyHeight; i++ {
+		screen.WriteString(sidebar[i])
+		screen.WriteString(list[i])
+		screen.WriteString("\r\n")
+	}
+
+	footer := " q quit  / search  f/F pin home/away  enter details  r refresh"
+	switch {
+	case a.searching:
+		footer = " Search: " + a.search + "_"
+	case a.status != "":
+		footer = " " + a.status
+	case a.search != "":
+		footer = fmt.Sprintf(" Filter: %q (esc to clear)", a.search)
+	}
+	screen.WriteString(dim(padRight(footer, a.width)))
+
+	fmt.Print(screen.String())
+}
+
+// matchLine is a one-line summary of a match for the list
+func (a *tuiApp) matchLine(event Event) string {
+	home, away, ok := homeAndAway(event.Competitions[0])
+	if !ok {
+		return event.Name
+	}
+
+	star := "  "
+	if a.isFavouriteMatch(event) {
+		star = "★ "
+	}
+
+	score := "vs"
+	when := event.Status.Type.ShortDetail
+	switch event.Status.Type.State {
+	case "in", "post":
+		score = fmt.Sprintf("%s-%s", home.Score, away.Score)
+	case "pre":
+		if t, err := parseEventTime(event.Date); err == nil {
+			when = t.Local().Format("15:04")
+		}
+	}
+
+	return fmt.Sprintf("%s%-8s %s %s %s", star, truncate(when, 8), home.Team.DisplayName, score, away.Team.DisplayName)
+}
+
+// summaryLines fetches a match summary and lays it out for the detail pane
+func summaryLines(ctx context.Context, event Event) ([]string, error) {
+	summary, err := fetchMatchSummary(ctx, eventLeagueSlug(event), event.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := []string{event.Name}
+	if home, away, ok := homeAndAway(event.Competitions[0]); ok {
+		lines[0] = fmt.Sprintf("%s %s - %s %s", home.Team.DisplayName, home.Score, away.Score, away.Team.DisplayName)
+	}
+	lines = append(lines, fmt.Sprintf("%s · %s", event.Status.Type.Detail, tuiLeagueName(event)))
+
+	if venue := summary.GameInfo.Venue.FullName; venue != "" {
+		info := "Venue: " + venue
+		if summary.GameInfo.Attendance > 0 {
+			info += fmt.Sprintf(" (attendance %d)", summary.GameInfo.Attendance)
+		}
+		lines = append(lines, info)
+	}
+	if t, err := parseEventTime(event.Date); err == nil {
+		lines = append(lines, "Kick-off: "+t.Local().Format("Mon 2 Jan 15:04"))
+	}
+
+	if len(summary.KeyEvents) > 0 {
+		lines = append(lines, "")
+	}
+	for _, ke := range summary.KeyEvents {
+		var who string
+		if len(ke.Participants) > 0 {
+			who = ke.Participants[0].Athlete.DisplayName
+		}
+		switch {
+		case who != "" && ke.Team.DisplayName != "":
+			who = fmt.Sprintf("%s (%s)", who, ke.Team.DisplayName)
+		case who == "":
+			who = ke.Team.DisplayName
+		}
+		if who == "" {
+			who = ke.Text
+		}
+		lines = append(lines, fmt.Sprintf("%-6s %-14s %s", ke.Clock.DisplayValue, truncate(ke.Type.Text, 14), who))
+	}
+
+	// Estimate the outcome of fixtures that haven't started
+	if home, away, ok := homeAndAway(event.Competitions[0]); ok && event.Status.Type.State == "pre" {
+		if prediction, ok := predictionFor(ctx, eventLeagueSlug(event), home.Team, away.Team); ok {
+			lines = append(lines, "", fmt.Sprintf("Prediction: home %.0f%%  draw %.0f%%  away %.0f%%",
+				prediction.HomeWin*100, prediction.Draw*100, prediction.AwayWin*100))
+		}
+	}
+	return lines, nil
+}
+
+// padRight pads or truncates s to exactly width runes
+func padRight(s string, width int) string {
+	s = truncate(s, width)
+	if n := utf8.RuneCountInString(s); n < width {
+		s += strings.Repeat(" ", width-n)
+	}
+	return s
+}
+
+// truncate shortens s to at most width runes
+func truncate(s string, width int) string {
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	if width <= 1 {
+		return string(runes[:width])
+	}
+	return string(runes[:width-1]) + "…"
+}
@@ -0,0 +1,319 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// verifyCmd represents the verify command
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Cross-check a matchday between two sources",
+	Long: `The 'verify' command fetches the same matchday from two sources, pairs the
+matches by teams and kick-off time, and reports where they disagree on the
+score, status or kick-off, and matches only one of them lists.
+
+The first source is ESPN's all-leagues feed. By default it is checked
+against ESPN's per-league feeds, which are served separately and don't
+share the all-leagues feed's occasional phantom scores. --against can
+name an ESPN-compatible mirror instead.
+
+Examples:
+  # Check today's matches
+  sharingan verify
+
+  # Check yesterday's Premier League results
+  sharingan verify --league eng.1 --date 2025-03-15
+
+  # Check against a mirror
+  sharingan verify --against https://site.web.api.espn.com
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runVerify(cmd.Context())
+	},
+}
+
+var verifyAgainst string
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+
+	verifyCmd.Flags().StringVarP(&league, "league", "l", "", "Only check this league (e.g. eng.1)")
+	verifyCmd.Flags().StringVarP(&date, "date", "d", "", "Matchday to check (YYYY-MM-DD, default today)")
+	verifyCmd.Flags().StringVar(&verifyAgainst, "against", "espn", `Second source: "espn" for ESPN's per-league feeds, or a mirror URL`)
+	verifyCmd.Flags().StringVarP(&format, "format", "f", "pretty", "Output format (pretty, json)")
+}
+
+// reconcileWindow is how far apart two sources' kick-off times may be for
+// their matches to still be paired
+const reconcileWindow = 24 * time.Hour
+
+// discrepancy is something two sources disagree on
+type discrepancy struct {
+	Match apiMatch `json:"match"`
+	// Field is score, status, kickoff or missing
+	Field     string `json:"field"`
+	Primary   string `json:"primary"`
+	Secondary string `json:"secondary"`
+}
+
+// verifyReport is the outcome of comparing two sources
+type verifyReport struct {
+	Date          string        `json:"date"`
+	Primary       string        `json:"primary"`
+	Secondary     string        `json:"secondary"`
+	Compared      int           `json:"compared"`
+	Discrepancies []discrepancy `json:"discrepancies"`
+}
+
+// secondarySource returns the provider named by --against style specs:
+// "espn" for ESPN's own per-league feeds, or a mirror URL
+func secondarySource(spec string, espn *espnProvider, cacheTTL time.Duration) (provider, string, error) {
+	switch {
+	case spec == "" || spec == "espn":
+		return espn, "ESPN league feeds", nil
+	case strings.HasPrefix(spec, "http://") || strings.HasPrefix(spec, "https://"):
+		mirror := newMirrorProvider(spec, cacheTTL)
+		return mirror, mirror.Name(), nil
+	}
+	return nil, "", errInvalidInput(`unknown source %q: use "espn" or a mirror URL`, spec)
+}
+
+// leagueScoreboards fetches each league's own scoreboard from a source
+func leagueScoreboards(ctx context.Context, p provider, leagues []string, day string) ([]apiMatch, []string, error) {
+	var matches []apiMatch
+	var covered []string
+	var lastErr error
+	for _, slug := range leagues {
+		events, err := p.Scoreboard(ctx, slug, day)
+		if err != nil {
+			slog.Warn("skipping league", "league", slug, "source", p.Name(), "err", err)
+			lastErr = err
+			continue
+		}
+		covered = append(covered, slug)
+		for _, event := range events {
+			m := normalizeMatch(event)
+			m.League = defaultIfEmpty(m.League, slug)
+			matches = append(matches, m)
+		}
+	}
+	if len(covered) == 0 && lastErr != nil {
+		return nil, nil, lastErr
+	}
+	return matches, covered, nil
+}
+
+func runVerify(ctx context.Context) error {
+	day := time.Now()
+	if date != "" {
+		var err error
+		if day, err = time.ParseInLocation("2006-01-02", date, time.Local); err != nil {
+			return errInvalidInput("invalid date %q: use YYYY-MM-DD", date)
+		}
+	}
+
+	espn := newESPNProvider(0)
+	secondary, secondaryName, err := secondarySource(verifyAgainst, espn, 0)
+	if err != nil {
+		return err
+	}
+
+	slog.Info("fetching matchday", "date", day.Format("2006-01-02"), "primary", "ESPN", "secondary", secondaryName)
+	events, err := espn.Scoreboard(ctx, "all", day.Format("20060102"))
+	if err != nil {
+		return err
+	}
+
+	var primary []apiMatch
+	leagues := make(map[string]bool)
+	for _, event := range events {
+		if !eventMatchesLeague(event, league) {
+			continue
+		}
+		m := normalizeMatch(event)
+		primary = append(primary, m)
+		if m.League != "" {
+			leagues[m.League] = true
+		}
+	}
+	if isLeagueSlug(league) {
+		leagues[strings.ToLower(strings.TrimSpace(league))] = true
+	}
+
+	slugs := make([]string, 0, len(leagues))
+	for slug := range leagues {
+		slugs = append(slugs, slug)
+	}
+	sort.Strings(slugs)
+
+	others, covered, err := leagueScoreboards(ctx, secondary, slugs, day.Format("20060102"))
+	if err != nil {
+		return err
+	}
+	// Only compare leagues both sources could answer for
+	primary = inLeagues(primary, covered)
+
+	compared, diffs := reconcileMatches(primary, others)
+	report := verifyReport{
+		Date:          day.Format("2006-01-02"),
+		Primary:       "ESPN all-leagues feed",
+		Secondary:     secondaryName,
+		Compared:      compared,
+		Discrepancies: diffs,
+	}
+	if report.Discrepancies == nil {
+		report.Discrepancies = []discrepancy{}
+	}
+
+	if format == "json" {
+		out, _ := json.MarshalIndent(report, "", "  ")
+		fmt.Println(string(out))
+		return nil
+	}
+	displayVerifyReport(report)
+	return nil
+}
+
+// inLeagues keeps the matches of the given leagues
+func inLeagues(matches []apiMatch, leagues []string) []apiMatch {
+	keep := make(map[string]bool, len(leagues))
+	for _, slug := range leagues {
+		keep[slug] = true
+	}
+	var kept []apiMatch
+	for _, m := range matches {
+		if keep[m.League] {
+			kept = append(kept, m)
+		}
+	}
+	return kept
+}
+
+// reconcileMatches pairs the matches of two sources and returns how many
+// pairs were compared and every difference found, including matches only
+// one source lists
+func reconcileMatches(primary, secondary []apiMatch) (int, []discrepancy) {
+	used := make([]bool, len(secondary))
+	compared := 0
+	var diffs []discrepancy
+
+	for _, p := range primary {
+		i := pairMatch(p, secondary, used)
+		if i < 0 {
+			diffs = append(diffs, discrepancy{Match: p, Field: "missing", Primary: "listed", Secondary: "not listed"})
+			continue
+		}
+		used[i] = true
+		compared++
+		diffs = append(diffs, compareMatches(p, secondary[i])...)
+	}
+	for i, s := range secondary {
+		if !used[i] {
+			diffs = append(diffs, discrepancy{Match: s, Field: "missing", Primary: "not listed", Secondary: "listed"})
+		}
+	}
+	return compared, diffs
+}
+
+// pairMatch finds the unused match in candidates that is the same fixture as
+// m: the same ID, or the same teams with the closest kick-off. It returns -1
+// when there is none.
+func pairMatch(m apiMatch, candidates []apiMatch, used []bool) int {
+	best, bestGap := -1, reconcileWindow
+	kickoff, kickoffErr := time.Parse(time.RFC3339, m.Kickoff)
+	for i, c := range candidates {
+		if used[i] {
+			continue
+		}
+		if m.ID != "" && c.ID == m.ID {
+			return i
+		}
+		if !sameTeam(m.Home, c.Home) || !sameTeam(m.Away, c.Away) {
+			continue
+		}
+		gap := time.Duration(0)
+		if other, err := time.Parse(time.RFC3339, c.Kickoff); err == nil && kickoffErr == nil {
+			gap = kickoff.Sub(other).Abs()
+		}
+		if gap <= bestGap {
+			best, bestGap = i, gap
+		}
+	}
+	return best
+}
+
+// sameTeam reports whether two sources refer to the same team, by ID or by
+// name ignoring case, punctuation and a club suffix
+func sameTeam(a, b apiSide) bool {
+	if a.ID != "" && a.ID == b.ID {
+		return true
+	}
+	return a.Name != "" && teamKey(a.Name) == teamKey(b.Name)
+}
+
+func teamKey(name string) string {
+	var words []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		switch word {
+		case "fc", "cf", "afc", "sc":
+			continue
+		}
+		words = append(words, word)
+	}
+	return strings.Join(words, "")
+}
+
+// compareMatches lists what two sources disagree on about the same match.
+// The status is compared by state, plus the final status (full-time,
+// postponed, ...) outside live play, since the live clock always drifts.
+func compareMatches(p, s apiMatch) []discrepancy {
+	var diffs []discrepancy
+	add := func(field, primary, secondary string) {
+		diffs = append(diffs, discrepancy{Match: p, Field: field, Primary: primary, Secondary: secondary})
+	}
+
+	if score, other := matchScore(p), matchScore(s); score != other {
+		add("score", score, other)
+	}
+	if p.State != s.State || (p.State != "in" && p.StatusName != s.StatusName) {
+		add("status", defaultIfEmpty(p.Status, p.State), defaultIfEmpty(s.Status, s.State))
+	}
+	if p.Kickoff != s.Kickoff {
+		add("kickoff", p.Kickoff, s.Kickoff)
+	}
+	return diffs
+}
+
+func matchScore(m apiMatch) string {
+	return scoreText(m.Home.Score) + "-" + scoreText(m.Away.Score)
+}
+
+func displayVerifyReport(r verifyReport) {
+	header := color.New(color.FgCyan, color.Bold).SprintFunc()
+	fmt.Printf("\n%s\n", header(fmt.Sprintf("VERIFY %s: %s vs %s", r.Date, r.Primary, r.Secondary)))
+	fmt.Println("=================================")
+
+	if len(r.Discrepancies) == 0 {
+		fmt.Printf("All %d matches agree.\n", r.Compared)
+		return
+	}
+
+	warn := color.New(color.FgRed, color.Bold).SprintFunc()
+	for _, d := range r.Discrepancies {
+		fmt.Printf("%s vs %s (%s)\n", d.Match.Home.Name, d.Match.Away.Name, defaultIfEmpty(d.Match.League, "?"))
+		fmt.Printf("  %s  %s: %s  %s: %s\n", warn(strings.ToUpper(d.Field)), "ESPN", d.Primary, r.Secondary, d.Secondary)
+	}
+	fmt.Printf("\nCompared %d matches: %d discrepancies\n", r.Compared, len(r.Discrepancies))
+}